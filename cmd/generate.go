@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/luncj/mess/dataset"
 	"github.com/luncj/mess/generator"
 	"github.com/luncj/mess/schema"
 )
@@ -18,11 +20,22 @@ func generateCmd() *cobra.Command {
 	var schemaPath string
 	var metadataPath string
 	var outputPath string
+	var seed int64
+	var dryRun bool
 
 	cmd := cobra.Command{
 		Use:   "generate",
 		Short: "Generate SQL with random data",
-		Run: func(*cobra.Command, []string) {
+		Run: func(cmd *cobra.Command, _ []string) {
+			if cmd.Flags().Changed("seed") {
+				dataset.Seed(seed)
+			}
+
+			if dryRun {
+				printPlan(schemaPath, numRows)
+				return
+			}
+
 			output, err := os.Create(outputPath)
 			if err != nil {
 				log.Fatalf("failed to open output file: %s", err)
@@ -66,10 +79,37 @@ func generateCmd() *cobra.Command {
 	f.StringVar(&metadataPath, "metadata-path", "./metadata.json", "Path of metadata for increment generating")
 	f.StringVar(&outputPath, "output-path", "output.sql", "Path of generated SQL")
 	f.UintVar(&numRows, "num-rows", 1_000, "Number of rows")
+	f.Int64Var(&seed, "seed", 0, "Seed of random data, random when not set")
+	f.BoolVar(&dryRun, "dry-run", false, "Validate the schema and report the rows to generate without generating them")
 
 	return &cmd
 }
 
+func printPlan(schemaPath string, numRows uint) {
+	s, err := schema.FromFile(schemaPath)
+	if err != nil {
+		log.Fatalf("failed to read schema from file: %s", err)
+	}
+
+	p, err := s.Plan(int(numRows))
+	if err != nil {
+		log.Fatalf("failed to plan rows: %s", err)
+	}
+
+	fmt.Printf("rows: %d\n", p.Count)
+	fmt.Printf("columns: %s\n", strings.Join(p.Columns, ", "))
+	for _, k := range p.KeySpaces {
+		size := "unbounded"
+		if k.Size != nil {
+			size = k.Size.String()
+		}
+		fmt.Printf("key (%s): %s distinct values\n", strings.Join(k.Keys, ", "), size)
+	}
+	for _, w := range p.Warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(generateCmd())
 }
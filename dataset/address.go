@@ -0,0 +1,60 @@
+package dataset
+
+import (
+	"fmt"
+)
+
+const (
+	AddressPartStreet = "street"
+	AddressPartCity   = "city"
+	AddressPartZip    = "zip"
+	AddressPartFull   = "full"
+)
+
+var streetNames = []string{
+	"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake",
+	"Hill", "Park", "Walnut", "Sunset", "Lincoln", "Jackson", "Church", "River",
+	"Highland", "Madison", "Jefferson", "Center", "Forest", "Spring", "Meadow", "Willow",
+}
+
+var streetSuffixes = []string{
+	"St", "Ave", "Rd", "Blvd", "Ln", "Dr", "Ct", "Way", "Pl", "Ter",
+}
+
+var cities = []string{
+	"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem",
+	"Madison", "Georgetown", "Arlington", "Ashland", "Burlington", "Manchester", "Oxford", "Milton",
+	"Newport", "Dover", "Hudson", "Kingston", "Winchester", "Lexington", "Jackson", "Auburn",
+}
+
+func (g *Generator) Address(part string) string {
+	switch part {
+	case AddressPartStreet:
+		return g.street()
+	case AddressPartCity:
+		return g.city()
+	case AddressPartZip:
+		return g.zip()
+	default:
+		return fmt.Sprintf("%s, %s %s", g.street(), g.city(), g.zip())
+	}
+}
+
+func Address(part string) string {
+	return std.Address(part)
+}
+
+func (g *Generator) street() string {
+	return fmt.Sprintf("%d %s %s",
+		g.r.Intn(9_999)+1,
+		streetNames[g.r.Intn(len(streetNames))],
+		streetSuffixes[g.r.Intn(len(streetSuffixes))])
+}
+
+func (g *Generator) city() string {
+	return cities[g.r.Intn(len(cities))]
+}
+
+func (g *Generator) zip() string {
+	return fmt.Sprintf("%05d", g.r.Intn(100_000))
+}
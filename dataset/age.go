@@ -0,0 +1,83 @@
+package dataset
+
+import (
+	"math"
+)
+
+const AgeDistributionAdult = "adult"
+
+// ageBands holds the age ranges of a population and their weights, skewed to
+// adults.
+var ageBands = []struct {
+	min, max, weight int
+}{
+	{0, 17, 22},
+	{18, 34, 23},
+	{35, 54, 26},
+	{55, 74, 21},
+	{75, 84, 6},
+	{85, 100, 2},
+}
+
+// Age returns an age within [min, max] drawn from the distribution, the adult
+// skewed bands of a population by default.
+func (g *Generator) Age(min, max int, distribution string) int {
+	switch distribution {
+	case DistributionUniform:
+		return g.Length(min, max)
+	case DistributionNormal:
+		mean := float64(min+max) / 2
+		v := int(math.Round(g.r.NormFloat64()*float64(max-min)/6 + mean))
+		if v < min {
+			return min
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+
+	// weights of the bands overlapping [min, max] by the overlapped ages
+	weights := make([]float64, len(ageBands))
+	total := 0.0
+	for i, b := range ageBands {
+		lo, hi := b.min, b.max
+		if lo < min {
+			lo = min
+		}
+		if hi > max {
+			hi = max
+		}
+		if lo > hi {
+			continue
+		}
+		weights[i] = float64(b.weight) * float64(hi-lo+1) / float64(b.max-b.min+1)
+		total += weights[i]
+	}
+	if total == 0 {
+		return g.Length(min, max)
+	}
+
+	n := g.r.Float64() * total
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if n -= w; n < 0 {
+			b := ageBands[i]
+			lo, hi := b.min, b.max
+			if lo < min {
+				lo = min
+			}
+			if hi > max {
+				hi = max
+			}
+			return g.Length(lo, hi)
+		}
+	}
+	return max
+}
+
+func Age(min, max int, distribution string) int {
+	return std.Age(min, max, distribution)
+}
@@ -0,0 +1,21 @@
+package dataset
+
+// Binary returns random bytes of length in [min, max].
+func (g *Generator) Binary(min, max int) []byte {
+	b := make([]byte, g.Length(min, max))
+	g.read(b)
+	return b
+}
+
+func Binary(min, max int) []byte {
+	return std.Binary(min, max)
+}
+
+// Length returns a random length in [min, max].
+func (g *Generator) Length(min, max int) int {
+	return min + g.r.Intn(max-min+1)
+}
+
+func Length(min, max int) int {
+	return std.Length(min, max)
+}
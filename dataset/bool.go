@@ -0,0 +1,14 @@
+package dataset
+
+const defaultTrueRate = 50
+
+func (g *Generator) Bool(trueRate int) bool {
+	if trueRate == 0 {
+		trueRate = defaultTrueRate
+	}
+	return g.Skip(trueRate)
+}
+
+func Bool(trueRate int) bool {
+	return std.Bool(trueRate)
+}
@@ -0,0 +1,17 @@
+package dataset
+
+import (
+	"fmt"
+)
+
+// Color returns a hex color like "#1a2b3c", or "#1a2b3c4d" with alpha.
+func (g *Generator) Color(alpha bool) string {
+	if alpha {
+		return fmt.Sprintf("#%08x", g.r.Uint32())
+	}
+	return fmt.Sprintf("#%06x", g.r.Intn(1<<24))
+}
+
+func Color(alpha bool) string {
+	return std.Color(alpha)
+}
@@ -0,0 +1,29 @@
+package dataset
+
+var (
+	companyAdjectives = []string{
+		"Bright", "Blue", "Golden", "Silver", "Northern", "Southern", "Pacific", "Atlantic",
+		"Summit", "Prime", "Noble", "Swift", "Clear", "Green", "Red", "Iron",
+		"Granite", "Crystal", "United", "Global", "Rapid", "Quiet", "Bold", "True",
+	}
+	companyNouns = []string{
+		"Harbor", "River", "Ridge", "Peak", "Valley", "Forest", "Bridge", "Stone",
+		"Field", "Lake", "Point", "Gate", "Works", "Labs", "Systems", "Dynamics",
+		"Logistics", "Partners", "Solutions", "Networks", "Foods", "Energy", "Capital", "Media",
+	}
+	companySuffixes = []string{"Inc", "LLC", "Ltd", "Corp", "GmbH", "Co", "Group", "PLC"}
+)
+
+// Company returns a company name like "Bright Harbor", followed by a legal
+// suffix like "LLC" when includeSuffix.
+func (g *Generator) Company(includeSuffix bool) string {
+	name := companyAdjectives[g.r.Intn(len(companyAdjectives))] + " " + companyNouns[g.r.Intn(len(companyNouns))]
+	if includeSuffix {
+		name += " " + companySuffixes[g.r.Intn(len(companySuffixes))]
+	}
+	return name
+}
+
+func Company(includeSuffix bool) string {
+	return std.Company(includeSuffix)
+}
@@ -0,0 +1,59 @@
+package dataset
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Decimal returns a fixed-point number within [min, max] with exactly scale
+// digits after the decimal point, e.g. "1234.56" for scale 2.
+func (g *Generator) Decimal(min, max *big.Int, scale int) string {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+
+	lo := new(big.Int).Mul(min, unit)
+	hi := new(big.Int).Mul(max, unit)
+
+	n := new(big.Int).Sub(hi, lo)
+	n.Add(n, big.NewInt(1))
+
+	v := new(big.Int).Add(lo, g.bigIntn(n))
+
+	return formatDecimal(v, scale)
+}
+
+func Decimal(min, max *big.Int, scale int) string {
+	return std.Decimal(min, max, scale)
+}
+
+// bigIntn returns a random integer in [0, n).
+func (g *Generator) bigIntn(n *big.Int) *big.Int {
+	b := make([]byte, len(n.Bytes())+8)
+	g.read(b)
+
+	r := new(big.Int).SetBytes(b)
+	return r.Mod(r, n)
+}
+
+func formatDecimal(v *big.Int, scale int) string {
+	digits := new(big.Int).Abs(v).String()
+	if scale == 0 {
+		if v.Sign() < 0 {
+			return "-" + digits
+		}
+		return digits
+	}
+
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	s := strings.Builder{}
+	if v.Sign() < 0 {
+		s.WriteString("-")
+	}
+	s.WriteString(digits[:len(digits)-scale])
+	s.WriteString(".")
+	s.WriteString(digits[len(digits)-scale:])
+
+	return s.String()
+}
@@ -0,0 +1,23 @@
+package dataset
+
+import (
+	"time"
+)
+
+// Duration returns a duration within [min, max] which is a multiple of
+// resolution, there should be such a multiple within the range.
+func (g *Generator) Duration(min, max, resolution time.Duration) time.Duration {
+	lo, hi := min/resolution, max/resolution
+	if lo*resolution < min {
+		lo++
+	}
+	if hi*resolution > max {
+		hi--
+	}
+
+	return time.Duration(g.r.Int63n(int64(hi-lo)+1)+int64(lo)) * resolution
+}
+
+func Duration(min, max, resolution time.Duration) time.Duration {
+	return std.Duration(min, max, resolution)
+}
@@ -0,0 +1,31 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+)
+
+var defaultEmailDomains = []string{
+	"example.com",
+	"example.net",
+	"example.org",
+	"test.com",
+	"test.org",
+}
+
+func (g *Generator) Email(domains []string) string {
+	if len(domains) == 0 {
+		domains = defaultEmailDomains
+	}
+
+	local := strings.ToLower(g.word())
+	if g.r.Intn(2) == 0 {
+		local = fmt.Sprintf("%s%d", local, g.r.Intn(10_000))
+	}
+
+	return fmt.Sprintf("%s@%s", local, domains[g.r.Intn(len(domains))])
+}
+
+func Email(domains []string) string {
+	return std.Email(domains)
+}
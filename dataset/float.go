@@ -2,10 +2,12 @@ package dataset
 
 import (
 	"log"
-	"math/rand"
+	"math"
 )
 
-func Float(precision, scale int) float64 {
+// Float returns a float within FloatBounds(precision, scale) rounded to scale
+// decimals.
+func (g *Generator) Float(precision, scale int) float64 {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Fatalf("failed to generate data for Float(%d, %d): %s", precision, scale, r)
@@ -18,8 +20,30 @@ func Float(precision, scale int) float64 {
 	min := - max
 
 	if max == 0 {
-		return rand.Float64()
+		return round(g.r.Float64(), scale)
 	}
 
-	return float64(int64(min)+rand.Int63n(int64(max-min))) + rand.Float64()
+	return round(float64(int64(min)+g.r.Int63n(int64(max-min)))+g.r.Float64(), scale)
+}
+
+func Float(precision, scale int) float64 {
+	return std.Float(precision, scale)
+}
+
+// FloatBounds returns the range Float(precision, scale) generates within.
+func FloatBounds(precision, scale int) (float64, float64) {
+	max := math.Pow10(precision-scale) - 1
+	if max <= 0 {
+		return 0, 1
+	}
+	return -max, max
+}
+
+// FloatRange returns a float within [min, max] rounded to scale decimals.
+func (g *Generator) FloatRange(min, max float64, scale int) float64 {
+	return clamp(round(min+g.r.Float64()*(max-min), scale), min, max)
+}
+
+func FloatRange(min, max float64, scale int) float64 {
+	return std.FloatRange(min, max, scale)
 }
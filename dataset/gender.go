@@ -0,0 +1,22 @@
+package dataset
+
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+	GenderOther  = "other"
+)
+
+// Gender returns male or female evenly, or other at otherRate percent.
+func (g *Generator) Gender(otherRate int) string {
+	if g.Skip(otherRate) {
+		return GenderOther
+	}
+	if g.r.Intn(2) == 0 {
+		return GenderMale
+	}
+	return GenderFemale
+}
+
+func Gender(otherRate int) string {
+	return std.Gender(otherRate)
+}
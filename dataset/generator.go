@@ -0,0 +1,130 @@
+package dataset
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Generator generates random data from its own source, generators seeded the
+// same generate the same data. A Generator is safe for concurrent use, but
+// goroutines sharing one contend for its source.
+type Generator struct {
+	r *rand.Rand
+
+	mu   sync.Mutex
+	seed int64
+	// derived holds the generators derived by name.
+	derived map[string]*Generator
+}
+
+func New(seed int64) *Generator {
+	return &Generator{
+		r:    rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)}),
+		seed: seed,
+	}
+}
+
+// std is the default generator used by the package-level functions.
+var std = New(time.Now().UnixNano())
+
+// Default returns the generator used by the package-level functions.
+func Default() *Generator {
+	return std
+}
+
+// Seed seeds the default generator, so that the package-level functions
+// generate the same data on every run. Note that the data is reproducible only
+// when it is generated by a single goroutine, since concurrent callers
+// interleave their draws from the source in no particular order. Use a
+// Generator per goroutine for reproducible concurrent generation.
+func Seed(seed int64) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+
+	std.r.Seed(seed)
+	std.seed = seed
+	std.derived = nil
+}
+
+// Derive returns the generator of name derived from g, which is seeded by the
+// 64-bit FNV-1a hash of the seed of g, as 8 little-endian bytes, followed by
+// name. Its data depends on the seed of g and name only, not on the data
+// generated by g or other derived generators. Derive returns the same
+// generator for the same name until g is seeded again.
+func (g *Generator) Derive(name string) *Generator {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if d, found := g.derived[name]; found {
+		return d
+	}
+
+	h := fnv.New64a()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(g.seed))
+	_, _ = h.Write(b[:])
+	_, _ = h.Write([]byte(name))
+
+	d := New(int64(h.Sum64()))
+	if g.derived == nil {
+		g.derived = make(map[string]*Generator)
+	}
+	g.derived[name] = d
+	return d
+}
+
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// read fills b with random bytes. Unlike rand.Read it keeps no state besides
+// the source, so it is as safe for concurrent use as the source is.
+func (g *Generator) read(b []byte) {
+	for i := 0; i < len(b); i += 8 {
+		v := g.r.Uint64()
+		for j := i; j < i+8 && j < len(b); j++ {
+			b[j] = byte(v)
+			v >>= 8
+		}
+	}
+}
+
+// Intn returns an int in [0, n).
+func (g *Generator) Intn(n int) int {
+	return g.r.Intn(n)
+}
+
+func Intn(n int) int {
+	return std.Intn(n)
+}
+
+// Int63 returns a non-negative int64, e.g. to seed another generator.
+func (g *Generator) Int63() int64 {
+	return g.r.Int63()
+}
+
+func Int63() int64 {
+	return std.Int63()
+}
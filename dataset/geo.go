@@ -0,0 +1,35 @@
+package dataset
+
+import (
+	"math"
+)
+
+// Geo returns a random coordinate rounded to precision decimals. The bbox is
+// [min_lng, min_lat, max_lng, max_lat] and is clamped to the valid ranges, a
+// zero bbox stands for the whole globe.
+func (g *Generator) Geo(bbox [4]float64, precision int) (lat, lng float64) {
+	if bbox == [4]float64{} {
+		bbox = [4]float64{-180, -90, 180, 90}
+	}
+
+	minLng, maxLng := clamp(bbox[0], -180, 180), clamp(bbox[2], -180, 180)
+	minLat, maxLat := clamp(bbox[1], -90, 90), clamp(bbox[3], -90, 90)
+
+	lat = round(minLat+g.r.Float64()*(maxLat-minLat), precision)
+	lng = round(minLng+g.r.Float64()*(maxLng-minLng), precision)
+
+	return clamp(lat, minLat, maxLat), clamp(lng, minLng, maxLng)
+}
+
+func Geo(bbox [4]float64, precision int) (lat, lng float64) {
+	return std.Geo(bbox, precision)
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+func round(v float64, scale int) float64 {
+	p := math.Pow10(scale)
+	return math.Round(v*p) / p
+}
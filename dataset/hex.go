@@ -0,0 +1,19 @@
+package dataset
+
+import (
+	"strings"
+)
+
+// Hex returns a random hex string of length characters, in uppercase when
+// upper.
+func (g *Generator) Hex(length int, upper bool) string {
+	s := g.Ascii(length, length+1, CharsetHex)
+	if upper {
+		return strings.ToUpper(s)
+	}
+	return s
+}
+
+func Hex(length int, upper bool) string {
+	return std.Hex(length, upper)
+}
@@ -2,11 +2,12 @@ package dataset
 
 import (
 	"log"
+	"math"
 	"math/big"
-	"math/rand"
 )
 
-func IntRange(min, max *big.Int) *big.Int {
+// IntRange returns an integer drawn uniformly from [min, max].
+func (g *Generator) IntRange(min, max *big.Int) *big.Int {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -14,18 +15,75 @@ func IntRange(min, max *big.Int) *big.Int {
 		}
 	}()
 
-	d := new(big.Int)
-	d.Sub(max, min)
-	d.Div(d, big.NewInt(2))
+	n := new(big.Int).Sub(max, min)
+	n.Add(n, big.NewInt(1))
+
+	r := new(big.Int).Rand(g.r, n)
+	return r.Add(r, min)
+}
+
+func IntRange(min, max *big.Int) *big.Int {
+	return std.IntRange(min, max)
+}
 
+const (
+	DistributionUniform     = "uniform"
+	DistributionNormal      = "normal"
+	DistributionExponential = "exponential"
+)
 
+// IntDistribution returns an integer drawn from the distribution and clamped
+// into [min, max]. Exponential distribution starts at min. A zero mean stands
+// for the middle of the range for normal distribution and a tenth of the range
+// above min for exponential distribution, a zero stdDev stands for a sixth of
+// the range.
+func (g *Generator) IntDistribution(min, max *big.Int, distribution string, mean, stdDev float64) *big.Int {
+	lo, _ := new(big.Float).SetInt(min).Float64()
+	hi, _ := new(big.Float).SetInt(max).Float64()
+	width := hi - lo
 
-	r := new(big.Int).Set(min)
-	if d.Int64() == 0 {
-		return r
+	var v float64
+	switch distribution {
+	case DistributionNormal:
+		if mean == 0 {
+			mean = lo + width/2
+		}
+		if stdDev == 0 {
+			stdDev = width / 6
+		}
+		v = g.r.NormFloat64()*stdDev + mean
+	case DistributionExponential:
+		if mean == 0 {
+			mean = lo + width/10
+		}
+		v = lo + g.r.ExpFloat64()*(mean-lo)
+	default:
+		return g.IntRange(min, max)
 	}
-	r.Add(r, big.NewInt(rand.Int63n(d.Int64())))
-	r.Add(r, big.NewInt(rand.Int63n(d.Int64())))
 
+	r, _ := big.NewFloat(math.Round(v)).Int(nil)
+	if r.Cmp(min) < 0 {
+		return r.Set(min)
+	}
+	if r.Cmp(max) > 0 {
+		return r.Set(max)
+	}
+	return r
+}
+
+func IntDistribution(min, max *big.Int, distribution string, mean, stdDev float64) *big.Int {
+	return std.IntDistribution(min, max, distribution, mean, stdDev)
+}
+
+// Multiple rounds v down to a multiple of step, or up to the next one when it
+// falls below min.
+func Multiple(v, min *big.Int, step int64) *big.Int {
+	s := big.NewInt(step)
+
+	r := new(big.Int).Div(v, s)
+	r.Mul(r, s)
+	if r.Cmp(min) < 0 {
+		r.Add(r, s)
+	}
 	return r
 }
@@ -0,0 +1,40 @@
+package dataset
+
+import (
+	"net"
+)
+
+// IPv4 returns a random IPv4 address within network, or any IPv4 address when
+// network is nil.
+func (g *Generator) IPv4(network *net.IPNet) string {
+	ip := make(net.IP, net.IPv4len)
+	g.read(ip)
+
+	if network != nil {
+		base := network.IP.To4()
+		mask := network.Mask
+		if len(mask) == net.IPv6len {
+			mask = mask[12:]
+		}
+		for i := range ip {
+			ip[i] = base[i]&mask[i] | ip[i]&^mask[i]
+		}
+	}
+
+	return ip.String()
+}
+
+func IPv4(network *net.IPNet) string {
+	return std.IPv4(network)
+}
+
+func (g *Generator) IPv6() string {
+	ip := make(net.IP, net.IPv6len)
+	g.read(ip)
+
+	return ip.String()
+}
+
+func IPv6() string {
+	return std.IPv6()
+}
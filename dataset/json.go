@@ -1,5 +1,72 @@
 package dataset
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 func JSON() string {
 	return "[[], [[]], [[], [], [[]]]]"
 }
+
+// JSONTree returns a JSON object nested up to maxDepth levels with keys
+// entries per level. The entries of inner levels alternate between objects and
+// arrays, the entries of the deepest level are random scalars. Arrays have keys
+// entries too unless arrayMax is positive, then they have [arrayMin, arrayMax]
+// entries.
+func (g *Generator) JSONTree(maxDepth, keys, arrayMin, arrayMax int) string {
+	t := jsonTree{g: g, maxDepth: maxDepth, keys: keys, arrayMin: arrayMin, arrayMax: arrayMax}
+	b, _ := json.Marshal(t.object(1))
+	return string(b)
+}
+
+func JSONTree(maxDepth, keys, arrayMin, arrayMax int) string {
+	return std.JSONTree(maxDepth, keys, arrayMin, arrayMax)
+}
+
+type jsonTree struct {
+	g                  *Generator
+	maxDepth, keys     int
+	arrayMin, arrayMax int
+}
+
+func (t jsonTree) object(depth int) map[string]interface{} {
+	o := make(map[string]interface{}, t.keys)
+	for i := 0; i < t.keys; i++ {
+		o[fmt.Sprintf("key%d", i+1)] = t.value(i, depth)
+	}
+	return o
+}
+
+func (t jsonTree) array(depth int) []interface{} {
+	n := t.keys
+	if t.arrayMax > 0 {
+		n = t.arrayMin + t.g.r.Intn(t.arrayMax-t.arrayMin+1)
+	}
+	a := make([]interface{}, n)
+	for i := range a {
+		a[i] = t.value(i, depth)
+	}
+	return a
+}
+
+func (t jsonTree) value(i, depth int) interface{} {
+	if depth < t.maxDepth {
+		if i%2 == 0 {
+			return t.object(depth + 1)
+		}
+		return t.array(depth + 1)
+	}
+
+	g := t.g
+	switch i % 4 {
+	case 0:
+		return g.word()
+	case 1:
+		return g.r.Intn(10_000)
+	case 2:
+		return g.r.Intn(2) == 0
+	default:
+		return round(g.r.Float64()*1_000, 2)
+	}
+}
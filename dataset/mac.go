@@ -0,0 +1,31 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+)
+
+const defaultMACSeparator = ":"
+
+// MAC returns a random unicast MAC address with the locally administered bit
+// set, so it never collides with a real vendor prefix.
+func (g *Generator) MAC(sep string) string {
+	if sep == "" {
+		sep = defaultMACSeparator
+	}
+
+	var b [6]byte
+	g.read(b[:])
+	b[0] = (b[0] | 0x02) &^ 0x01
+
+	octets := make([]string, len(b))
+	for i := range b {
+		octets[i] = fmt.Sprintf("%02x", b[i])
+	}
+
+	return strings.Join(octets, sep)
+}
+
+func MAC(sep string) string {
+	return std.MAC(sep)
+}
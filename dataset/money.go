@@ -0,0 +1,21 @@
+package dataset
+
+import (
+	"math/big"
+)
+
+// Money returns an amount of minor units within [min, max], e.g. cents, as a
+// fixed-point number with scale digits after the decimal point, e.g. "12.34"
+// for 1234 and scale 2.
+func (g *Generator) Money(min, max int64, scale int) string {
+	n := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	n.Add(n, big.NewInt(1))
+
+	v := new(big.Int).Add(big.NewInt(min), g.bigIntn(n))
+
+	return formatDecimal(v, scale)
+}
+
+func Money(min, max int64, scale int) string {
+	return std.Money(min, max, scale)
+}
@@ -0,0 +1,100 @@
+package dataset
+
+import (
+	"fmt"
+)
+
+const (
+	NamePartFirst = "first"
+	NamePartLast  = "last"
+	NamePartFull  = "full"
+)
+
+const defaultNameLocale = "en"
+
+type names struct {
+	male   []string
+	female []string
+	last   []string
+}
+
+var namesByLocale = map[string]names{
+	"en": {
+		male: []string{
+			"James", "John", "Robert", "Michael", "William", "David", "Richard", "Joseph",
+			"Thomas", "Charles", "Christopher", "Daniel", "Matthew", "Anthony", "Mark", "Donald",
+			"Steven", "Paul", "Andrew", "Joshua", "Kenneth", "Kevin", "Brian", "George",
+			"Edward", "Ronald", "Timothy", "Jason", "Jeffrey", "Ryan", "Jacob", "Gary",
+		},
+		female: []string{
+			"Mary", "Patricia", "Jennifer", "Linda", "Elizabeth", "Barbara", "Susan", "Jessica",
+			"Sarah", "Karen", "Nancy", "Lisa", "Betty", "Margaret", "Sandra", "Ashley",
+			"Kimberly", "Emily", "Donna", "Michelle", "Dorothy", "Carol", "Amanda", "Melissa",
+			"Deborah", "Stephanie", "Rebecca", "Sharon", "Laura", "Cynthia", "Kathleen", "Amy",
+		},
+		last: []string{
+			"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+			"Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas",
+			"Taylor", "Moore", "Jackson", "Martin", "Lee", "Perez", "Thompson", "White",
+			"Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson", "Walker", "Young",
+			"Allen", "King", "Wright", "Scott", "Torres", "Nguyen", "Hill", "Flores",
+			"Green", "Adams", "Nelson", "Baker", "Hall", "Rivera", "Campbell", "Mitchell",
+			"Carter", "Roberts", "Gomez", "Phillips", "Evans", "Turner", "Diaz", "Parker",
+			"Cruz", "Edwards", "Collins", "Reyes", "Stewart", "Morris", "Morales", "Murphy",
+		},
+	},
+}
+
+// IsNameLocale reports whether names of the locale are available, an empty
+// locale stands for the default one.
+func IsNameLocale(locale string) bool {
+	if locale == "" {
+		return true
+	}
+	_, ok := namesByLocale[locale]
+	return ok
+}
+
+func (g *Generator) Name(part, locale string) string {
+	return g.GenderedName(part, locale, "")
+}
+
+func Name(part, locale string) string {
+	return std.Name(part, locale)
+}
+
+// GenderedName returns a name of which the first name agrees with the gender,
+// the first name is of any gender for other or empty genders.
+func (g *Generator) GenderedName(part, locale, gender string) string {
+	if locale == "" {
+		locale = defaultNameLocale
+	}
+	n := namesByLocale[locale]
+
+	var first string
+	switch gender {
+	case GenderMale:
+		first = n.male[g.r.Intn(len(n.male))]
+	case GenderFemale:
+		first = n.female[g.r.Intn(len(n.female))]
+	default:
+		if i := g.r.Intn(len(n.male) + len(n.female)); i < len(n.male) {
+			first = n.male[i]
+		} else {
+			first = n.female[i-len(n.male)]
+		}
+	}
+
+	switch part {
+	case NamePartFirst:
+		return first
+	case NamePartLast:
+		return n.last[g.r.Intn(len(n.last))]
+	default:
+		return fmt.Sprintf("%s %s", first, n.last[g.r.Intn(len(n.last))])
+	}
+}
+
+func GenderedName(part, locale, gender string) string {
+	return std.GenderedName(part, locale, gender)
+}
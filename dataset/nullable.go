@@ -1,5 +1,11 @@
 package dataset
 
+// Nullable draws from the source of the generator, so the NULL positions are
+// reproduced with the same seed.
+func (g *Generator) Nullable(rate int) bool {
+	return g.Skip(rate)
+}
+
 func Nullable(rate int) bool {
-	return Skip(rate)
+	return std.Nullable(rate)
 }
@@ -0,0 +1,31 @@
+package dataset
+
+import (
+	"strings"
+)
+
+var defaultExtensions = []string{"jpg", "png", "gif", "pdf", "txt", "csv", "doc", "zip"}
+
+// Path returns an absolute path of depth directories and a file, named by
+// lowercase words of the dictionary, with one of extensions, or a common
+// extension when none is given.
+func (g *Generator) Path(depth int, extensions []string) string {
+	if len(extensions) == 0 {
+		extensions = defaultExtensions
+	}
+
+	s := strings.Builder{}
+	for i := 0; i <= depth; i++ {
+		s.WriteString("/")
+		s.WriteString(strings.ToLower(g.word()))
+	}
+	if ext := strings.TrimPrefix(extensions[g.r.Intn(len(extensions))], "."); ext != "" {
+		s.WriteString(".")
+		s.WriteString(ext)
+	}
+	return s.String()
+}
+
+func Path(depth int, extensions []string) string {
+	return std.Path(depth, extensions)
+}
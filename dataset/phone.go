@@ -0,0 +1,47 @@
+package dataset
+
+import (
+	"strings"
+)
+
+const defaultPhoneCountry = "US"
+
+var phoneFormats = map[string]string{
+	"US": "(###) ###-####",
+	"CA": "(###) ###-####",
+	"UK": "0## #### ####",
+	"GB": "0## #### ####",
+	"DE": "0### #######",
+	"FR": "0# ## ## ## ##",
+	"JP": "0##-####-####",
+	"CN": "1## #### ####",
+	"IN": "##### #####",
+	"AU": "0# #### ####",
+}
+
+// PhoneFormat returns the default phone format of the country, which defaults
+// to US when country is empty.
+func PhoneFormat(country string) (string, bool) {
+	if country == "" {
+		country = defaultPhoneCountry
+	}
+	format, ok := phoneFormats[strings.ToUpper(country)]
+	return format, ok
+}
+
+// Phone replaces every '#' in format with a random digit.
+func (g *Generator) Phone(format string) string {
+	s := strings.Builder{}
+	for _, r := range format {
+		if r == '#' {
+			s.WriteByte(byte('0' + g.r.Intn(10)))
+		} else {
+			s.WriteRune(r)
+		}
+	}
+	return s.String()
+}
+
+func Phone(format string) string {
+	return std.Phone(format)
+}
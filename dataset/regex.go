@@ -0,0 +1,124 @@
+package dataset
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxRepeat bounds the repetition of unbounded quantifiers like * and +.
+const maxRepeat = 10
+
+// FromRegex returns a random string matching pattern.
+func (g *Generator) FromRegex(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	s := strings.Builder{}
+	if err := g.writeRegex(&s, re.Simplify()); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+func FromRegex(pattern string) (string, error) {
+	return std.FromRegex(pattern)
+}
+
+func (g *Generator) writeRegex(s *strings.Builder, re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return nil
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			s.WriteRune(r)
+		}
+		return nil
+	case syntax.OpCharClass:
+		r, ok := g.pickRune(re.Rune)
+		if !ok {
+			return fmt.Errorf("empty character class %s", re)
+		}
+		s.WriteRune(r)
+		return nil
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		s.WriteRune(rune(' ' + g.r.Intn('~'-' '+1)))
+		return nil
+	case syntax.OpCapture:
+		return g.writeRegex(s, re.Sub[0])
+	case syntax.OpStar:
+		return g.repeatRegex(s, re.Sub[0], 0, maxRepeat)
+	case syntax.OpPlus:
+		return g.repeatRegex(s, re.Sub[0], 1, maxRepeat)
+	case syntax.OpQuest:
+		return g.repeatRegex(s, re.Sub[0], 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + maxRepeat
+		}
+		return g.repeatRegex(s, re.Sub[0], re.Min, max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.writeRegex(s, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpAlternate:
+		return g.writeRegex(s, re.Sub[g.r.Intn(len(re.Sub))])
+	default:
+		return fmt.Errorf("unsupported regex %s", re)
+	}
+}
+
+func (g *Generator) repeatRegex(s *strings.Builder, re *syntax.Regexp, min, max int) error {
+	for n := min + g.r.Intn(max-min+1); n > 0; n-- {
+		if err := g.writeRegex(s, re); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pickRune picks a rune from the ranges of a character class, preferring the
+// printable ASCII ones so negated classes don't produce arbitrary code points.
+func (g *Generator) pickRune(ranges []rune) (rune, bool) {
+	var printable []rune
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < ' ' {
+			lo = ' '
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+
+	total := 0
+	for i := 0; i < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	n := g.r.Intn(total)
+	for i := 0; i < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n), true
+		}
+		n -= size
+	}
+	return 0, false
+}
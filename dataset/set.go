@@ -1,24 +1,64 @@
 package dataset
 
 import (
-	"math/rand"
+	"sort"
 	"strings"
 )
 
-func Set(options []string) string {
-	var selected []string
+// Set picks between min and max distinct options and joins them in the order
+// of declaration.
+func (g *Generator) Set(options []string, min, max int) string {
+	return strings.Join(g.Subset(options, min, max), ",")
+}
 
-	idx := 0
-	for {
-		idx = idx + rand.Intn(len(options))
-		if idx >= len(options) {
-			break
-		}
-		selected = append(selected, options[idx])
+func Set(options []string, min, max int) string {
+	return std.Set(options, min, max)
+}
+
+// Subset picks between min and max distinct options in the order of
+// declaration.
+func (g *Generator) Subset(options []string, min, max int) []string {
+	n := min + g.r.Intn(max-min+1)
+
+	indexes := g.r.Perm(len(options))[:n]
+	sort.Ints(indexes)
+
+	selected := make([]string, n)
+	for i, idx := range indexes {
+		selected[i] = options[idx]
 	}
-	return strings.Join(selected, ",")
+	return selected
+}
+
+func Subset(options []string, min, max int) []string {
+	return std.Subset(options, min, max)
+}
+
+func (g *Generator) Enum(options []string) string {
+	return options[g.r.Intn(len(options))]
 }
 
 func Enum(options []string) string {
-	return options[rand.Intn(len(options))]
+	return std.Enum(options)
+}
+
+// WeightedEnum picks an option with the probability proportional to its weight.
+func (g *Generator) WeightedEnum(options []string, weights []int) string {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	n := g.r.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return options[i]
+		}
+		n -= w
+	}
+	return options[len(options)-1]
+}
+
+func WeightedEnum(options []string, weights []int) string {
+	return std.WeightedEnum(options, weights)
 }
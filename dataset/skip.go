@@ -1,7 +1,9 @@
 package dataset
 
-import "math/rand"
+func (g *Generator) Skip(rate int) bool {
+	return g.r.Intn(100) < rate
+}
 
 func Skip(rate int) bool {
-	return rand.Intn(100) < rate
+	return std.Skip(rate)
 }
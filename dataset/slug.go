@@ -0,0 +1,18 @@
+package dataset
+
+import (
+	"strings"
+)
+
+// Slug returns n lowercase words of the dictionary joined by hyphens.
+func (g *Generator) Slug(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = strings.ToLower(g.word())
+	}
+	return strings.Join(words, "-")
+}
+
+func Slug(n int) string {
+	return std.Slug(n)
+}
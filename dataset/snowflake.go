@@ -0,0 +1,12 @@
+package dataset
+
+const (
+	SnowflakeMachineBits  = 10
+	SnowflakeSequenceBits = 12
+)
+
+// Snowflake packs the milliseconds since an epoch, a machine id and a sequence
+// number of the millisecond into a Snowflake id.
+func Snowflake(ms, machineID, sequence int64) int64 {
+	return ms<<(SnowflakeMachineBits+SnowflakeSequenceBits) | machineID<<SnowflakeSequenceBits | sequence
+}
@@ -1,46 +1,89 @@
 package dataset
 
 import (
-	"math/rand"
 	"strings"
+)
+
+const (
+	CharsetAlpha        = "alpha"
+	CharsetNumeric      = "numeric"
+	CharsetAlphanumeric = "alphanumeric"
+	CharsetHex          = "hex"
+)
 
-	"github.com/bxcodec/faker/v3"
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "~=+%^*/()[]{}/!@#$?| "
 )
 
-func Ascii(min, max int) string {
-	charset := []rune("abcdefghijklmnopqrstuvwxyz" + "ABCDEFGHIJKLMNOPQRSTUVWXYZ" + "0123456789" + "~=+%^*/()[]{}/!@#$?| ")
+var charsets = map[string]string{
+	"":                  lowerChars + upperChars + digitChars + symbolChars,
+	CharsetAlpha:        lowerChars + upperChars,
+	CharsetNumeric:      digitChars,
+	CharsetAlphanumeric: lowerChars + upperChars + digitChars,
+	CharsetHex:          "0123456789abcdef",
+}
 
-	l := int(rand.Int63n(int64(max-min))) + min
+// Ascii returns a string of length in [min, max) composed of the named charset
+// or, when charset is not a known name, of the characters of charset itself.
+func (g *Generator) Ascii(min, max int, charset string) string {
+	chars, ok := charsets[charset]
+	if !ok {
+		chars = charset
+	}
+	return g.randomString(min, max, []rune(chars))
+}
+
+func Ascii(min, max int, charset string) string {
+	return std.Ascii(min, max, charset)
+}
+
+func (g *Generator) randomString(min, max int, charset []rune) string {
+	l := int(g.r.Int63n(int64(max-min))) + min
 
 	s := strings.Builder{}
 	for i := 0; i < l; i++ {
-		idx := rand.Intn(len(charset))
+		idx := g.r.Intn(len(charset))
 		s.WriteRune(charset[idx])
 	}
 
 	return s.String()
 }
 
-func WordN(n int) string {
+func (g *Generator) WordN(n int) string {
 	words := make([]string, n)
 	for i := 0; i < n; i++ {
-		words[i] = faker.Word()
+		words[i] = g.word()
 	}
 	return strings.Join(words, ", ")
 }
 
-func SentenceN(n int) string {
+func WordN(n int) string {
+	return std.WordN(n)
+}
+
+func (g *Generator) SentenceN(n int) string {
 	s := make([]string, n)
 	for i := 0; i < n; i++ {
-		s[i] = faker.Sentence()
+		s[i] = g.sentence()
 	}
 	return strings.Join(s, " ")
 }
 
-func ParagraphN(n int) string {
+func SentenceN(n int) string {
+	return std.SentenceN(n)
+}
+
+func (g *Generator) ParagraphN(n int) string {
 	p := make([]string, n)
 	for i := 0; i < n; i++ {
-		p[i] = faker.Paragraph()
+		p[i] = g.paragraph()
 	}
 	return strings.Join(p, " ")
 }
+
+func ParagraphN(n int) string {
+	return std.ParagraphN(n)
+}
@@ -1,14 +1,94 @@
 package dataset
 
 import (
-	"math/rand"
 	"time"
 )
 
-func DateTime() time.Time {
-	min := time.Date(1970, 1, 0, 0, 0, 0, 0, time.UTC).Unix()
-	max := time.Date(2038, 1, 19, 3, 14, 17, 0, time.UTC).Unix()
+var (
+	minDateTime = time.Date(1970, 1, 0, 0, 0, 0, 0, time.UTC)
+	maxDateTime = time.Date(2038, 1, 19, 3, 14, 17, 0, time.UTC)
+)
+
+// DateTimeBounds returns the range DateTime generates within.
+func DateTimeBounds() (time.Time, time.Time) {
+	return minDateTime, maxDateTime
+}
+
+// DateTime returns a time within the bounds in UTC, so that the generated times
+// do not depend on the local timezone.
+func (g *Generator) DateTime() time.Time {
+	min := minDateTime.Unix()
+	max := maxDateTime.Unix()
 	d := max - min
 
-	return time.Unix(rand.Int63n(d)+min, 0)
+	return time.Unix(g.r.Int63n(d)+min, 0).UTC()
+}
+
+func DateTime() time.Time {
+	return std.DateTime()
+}
+
+// DateTimeRange returns a time within [min, max] in the location of min, at
+// the precision of seconds.
+func (g *Generator) DateTimeRange(min, max time.Time) time.Time {
+	d := max.Unix() - min.Unix()
+
+	return time.Unix(g.r.Int63n(d+1)+min.Unix(), 0).In(min.Location())
+}
+
+func DateTimeRange(min, max time.Time) time.Time {
+	return std.DateTimeRange(min, max)
+}
+
+const (
+	businessHoursStart = 9
+	businessHoursEnd   = 17
+	// businessHoursRate is the percentage of business hours times.
+	businessHoursRate = 90
+	// weekdayAttempts limits the draws of a time on one of the weekdays.
+	weekdayAttempts = 100
+)
+
+// DateTimeBiased returns a time within [min, max] like DateTimeRange, on one of
+// the weekdays when given and, with businessHours, mostly between 9:00 and
+// 17:00 in the location of min. A range without any of the weekdays ignores
+// them.
+func (g *Generator) DateTimeBiased(min, max time.Time, weekdays []time.Weekday, businessHours bool) time.Time {
+	allowed := func(t time.Time) bool {
+		if len(weekdays) == 0 {
+			return true
+		}
+		for _, d := range weekdays {
+			if t.Weekday() == d {
+				return true
+			}
+		}
+		return false
+	}
+
+	t := g.DateTimeRange(min, max)
+	for i := 1; i < weekdayAttempts && !allowed(t); i++ {
+		t = g.DateTimeRange(min, max)
+	}
+
+	if businessHours && g.r.Intn(100) < businessHoursRate {
+		y, m, d := t.Date()
+		start := time.Date(y, m, d, businessHoursStart, 0, 0, 0, t.Location())
+		end := time.Date(y, m, d, businessHoursEnd, 0, 0, 0, t.Location())
+		if start.Before(min) {
+			start = min
+		}
+		if end.After(max) {
+			end = max
+		}
+		if !end.Before(start) {
+			t = g.DateTimeRange(start, end)
+		}
+	}
+
+	return t
+}
+
+func DateTimeBiased(min, max time.Time, weekdays []time.Weekday, businessHours bool) time.Time {
+	return std.DateTimeBiased(min, max, weekdays, businessHours)
 }
@@ -0,0 +1,77 @@
+package dataset
+
+import (
+	"strings"
+)
+
+const (
+	UnicodeBlockLatin         = "latin"
+	UnicodeBlockLatinExtended = "latin-extended"
+	UnicodeBlockGreek         = "greek"
+	UnicodeBlockCyrillic      = "cyrillic"
+	UnicodeBlockArabic        = "arabic"
+	UnicodeBlockHiragana      = "hiragana"
+	UnicodeBlockKatakana      = "katakana"
+	UnicodeBlockCJK           = "cjk"
+	UnicodeBlockHangul        = "hangul"
+	UnicodeBlockEmoji         = "emoji"
+)
+
+// unicodeBlocks holds the inclusive rune ranges of the blocks, unassigned code
+// points are left out.
+var unicodeBlocks = map[string][][2]rune{
+	UnicodeBlockLatin:         {{'a', 'z'}, {'A', 'Z'}},
+	UnicodeBlockLatinExtended: {{0x00C0, 0x00D6}, {0x00D8, 0x00F6}, {0x00F8, 0x017F}},
+	UnicodeBlockGreek:         {{0x0391, 0x03A1}, {0x03A3, 0x03A9}, {0x03B1, 0x03C9}},
+	UnicodeBlockCyrillic:      {{0x0410, 0x044F}},
+	UnicodeBlockArabic:        {{0x0621, 0x063A}, {0x0641, 0x064A}},
+	UnicodeBlockHiragana:      {{0x3041, 0x3096}},
+	UnicodeBlockKatakana:      {{0x30A1, 0x30FA}},
+	UnicodeBlockCJK:           {{0x4E00, 0x9FFF}},
+	UnicodeBlockHangul:        {{0xAC00, 0xD7A3}},
+	UnicodeBlockEmoji:         {{0x1F600, 0x1F64F}},
+}
+
+// IsUnicodeBlock reports whether runes of the block are available.
+func IsUnicodeBlock(block string) bool {
+	_, ok := unicodeBlocks[block]
+	return ok
+}
+
+// Unicode returns a string of length in [min, max) runes drawn evenly from the
+// runes of the blocks, latin-extended when no block is given.
+func (g *Generator) Unicode(min, max int, blocks []string) string {
+	if len(blocks) == 0 {
+		blocks = []string{UnicodeBlockLatinExtended}
+	}
+
+	var ranges [][2]rune
+	total := 0
+	for _, b := range blocks {
+		for _, r := range unicodeBlocks[b] {
+			ranges = append(ranges, r)
+			total += int(r[1]-r[0]) + 1
+		}
+	}
+
+	l := int(g.r.Int63n(int64(max-min))) + min
+
+	s := strings.Builder{}
+	for i := 0; i < l; i++ {
+		n := g.r.Intn(total)
+		for _, r := range ranges {
+			if size := int(r[1]-r[0]) + 1; n >= size {
+				n -= size
+				continue
+			}
+			s.WriteRune(r[0] + rune(n))
+			break
+		}
+	}
+
+	return s.String()
+}
+
+func Unicode(min, max int, blocks []string) string {
+	return std.Unicode(min, max, blocks)
+}
@@ -0,0 +1,37 @@
+package dataset
+
+import (
+	"fmt"
+	"strings"
+)
+
+var defaultURLSchemes = []string{"https", "http"}
+
+var topLevelDomains = []string{"com", "net", "org", "io", "dev"}
+
+func (g *Generator) URL(schemes []string, includeQuery bool) string {
+	if len(schemes) == 0 {
+		schemes = defaultURLSchemes
+	}
+
+	u := strings.Builder{}
+	u.WriteString(fmt.Sprintf("%s://%s.%s",
+		schemes[g.r.Intn(len(schemes))],
+		strings.ToLower(g.word()),
+		topLevelDomains[g.r.Intn(len(topLevelDomains))]))
+
+	for i := g.r.Intn(3) + 1; i > 0; i-- {
+		u.WriteString("/")
+		u.WriteString(strings.ToLower(g.word()))
+	}
+
+	if includeQuery {
+		u.WriteString(fmt.Sprintf("?%s=%s", strings.ToLower(g.word()), strings.ToLower(g.word())))
+	}
+
+	return u.String()
+}
+
+func URL(schemes []string, includeQuery bool) string {
+	return std.URL(schemes, includeQuery)
+}
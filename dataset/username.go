@@ -0,0 +1,30 @@
+package dataset
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Username returns two lowercase words of the dictionary joined by an
+// underscore, followed by up to three digits when allowDigits, cut to
+// maxLength characters when positive.
+func (g *Generator) Username(maxLength int, allowDigits bool) string {
+	parts := []string{strings.ToLower(g.word()), strings.ToLower(g.word())}
+	if allowDigits {
+		parts = append(parts, strconv.Itoa(g.r.Intn(1000)))
+	}
+	return Truncate(strings.Join(parts, "_"), maxLength)
+}
+
+func Username(maxLength int, allowDigits bool) string {
+	return std.Username(maxLength, allowDigits)
+}
+
+// Truncate cuts s to maxLength characters when maxLength is positive.
+func Truncate(s string, maxLength int) string {
+	r := []rune(s)
+	if maxLength <= 0 || len(r) <= maxLength {
+		return s
+	}
+	return string(r[:maxLength])
+}
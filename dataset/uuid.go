@@ -0,0 +1,20 @@
+package dataset
+
+import (
+	"fmt"
+)
+
+// UUID returns a random (version 4) UUID in its canonical form.
+func (g *Generator) UUID() string {
+	var b [16]byte
+	g.read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func UUID() string {
+	return std.UUID()
+}
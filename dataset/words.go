@@ -0,0 +1,98 @@
+package dataset
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var defaultWords = []string{
+	"a", "ab", "accusantium", "ad", "alias", "aliquam", "amet", "animi", "aperiam", "architecto",
+	"asperiores", "aspernatur", "assumenda", "at", "atque", "aut", "autem", "beatae", "blanditiis", "commodi",
+	"consectetur", "consequatur", "consequuntur", "corporis", "corrupti", "culpa", "cum", "cumque", "cupiditate", "debitis",
+	"delectus", "deleniti", "deserunt", "dicta", "dignissimos", "distinctio", "dolor", "dolore", "dolorem", "doloremque",
+	"dolores", "doloribus", "dolorum", "ducimus", "ea", "eaque", "earum", "eius", "eligendi", "enim",
+	"eos", "error", "esse", "est", "et", "eum", "eveniet", "ex", "excepturi", "exercitationem",
+	"expedita", "explicabo", "facere", "facilis", "fuga", "fugiat", "fugit", "harum", "hic", "id",
+	"illo", "illum", "impedit", "in", "incidunt", "inventore", "ipsa", "ipsam", "ipsum", "iste",
+	"itaque", "iure", "iusto", "labore", "laboriosam", "laudantium", "libero", "magnam", "magni", "maiores",
+	"maxime", "minima", "minus", "modi", "molestiae", "molestias", "mollitia", "nam", "natus", "necessitatibus",
+	"nemo", "neque", "nesciunt", "nihil", "nisi", "nobis", "non", "nostrum", "nulla", "numquam",
+	"obcaecati", "odio", "odit", "officia", "officiis", "omnis", "optio", "pariatur", "perferendis", "perspiciatis",
+	"placeat", "porro", "possimus", "praesentium", "provident", "quae", "quaerat", "quam", "quas", "quasi",
+	"qui", "quia", "quibusdam", "quidem", "quis", "quisquam", "quo", "quod", "quos", "ratione",
+	"recusandae", "reiciendis", "rem", "repellat", "repellendus", "reprehenderit", "repudiandae", "rerum", "saepe", "sapiente",
+	"sed", "sequi", "similique", "sint", "sit", "soluta", "sunt", "suscipit", "tempora", "tempore",
+	"temporibus", "tenetur", "totam", "ullam", "unde", "ut", "vel", "velit", "veniam", "veritatis",
+	"vero", "vitae", "voluptas", "voluptate", "voluptatem", "voluptates", "voluptatibus", "voluptatum",
+}
+
+const (
+	maxSentenceWords      = 10
+	maxParagraphSentences = 10
+)
+
+var dictionary = struct {
+	sync.RWMutex
+	words []string
+}{words: defaultWords}
+
+// SetDictionary replaces the words of words, sentences and paragraphs, an empty
+// dictionary restores the default one.
+func SetDictionary(words []string) {
+	if len(words) == 0 {
+		words = defaultWords
+	}
+	dictionary.Lock()
+	dictionary.words = append([]string(nil), words...)
+	dictionary.Unlock()
+}
+
+func (g *Generator) word() string {
+	dictionary.RLock()
+	defer dictionary.RUnlock()
+	return dictionary.words[g.r.Intn(len(dictionary.words))]
+}
+
+func (g *Generator) sentence() string {
+	s := make([]string, g.r.Intn(maxSentenceWords)+1)
+	for i := range s {
+		s[i] = g.word()
+	}
+	s[0] = strings.Title(s[0])
+	return strings.Join(s, " ") + "."
+}
+
+func (g *Generator) paragraph() string {
+	p := make([]string, g.r.Intn(maxParagraphSentences)+1)
+	for i := range p {
+		p[i] = g.sentence()
+	}
+	return strings.Join(p, " ")
+}
+
+// MaxWordLength returns the length in characters of the longest word of the
+// dictionary.
+func MaxWordLength() int {
+	dictionary.RLock()
+	defer dictionary.RUnlock()
+	var max int
+	for _, w := range dictionary.words {
+		if n := utf8.RuneCountInString(w); n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// MaxSentenceLength returns the length in characters of the longest sentence.
+func MaxSentenceLength() int {
+	// The words are separated by spaces and followed by a period.
+	return maxSentenceWords * (MaxWordLength() + 1)
+}
+
+// MaxParagraphLength returns the length in characters of the longest
+// paragraph.
+func MaxParagraphLength() int {
+	return maxParagraphSentences*(MaxSentenceLength()+1) - 1
+}
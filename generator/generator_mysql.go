@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -67,7 +68,8 @@ func (m *MySQLGenerator) generate(md *Metadata, s *schema.Schema, dml schema.DML
 	}
 }
 
-func (m *MySQLGenerator) normalize(t schema.FieldType, value interface{}) string {
+func (m *MySQLGenerator) normalize(f schema.Field, value interface{}) string {
+	t := f.Type
 	switch v := value.(type) {
 	case string:
 		return fmt.Sprintf("'%s'", v)
@@ -76,6 +78,9 @@ func (m *MySQLGenerator) normalize(t schema.FieldType, value interface{}) string
 		case schema.FieldTypeDate:
 			return fmt.Sprintf("'%s'", v.Format("2006-01-02"))
 		case schema.FieldTypeDateTime:
+			if f.Location() != nil {
+				return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05-07:00"))
+			}
 			return fmt.Sprintf("'%s'", v.Format("2006-01-02 15:04:05"))
 		case schema.FieldTypeTime:
 			return fmt.Sprintf("'%s'", v.Format("15:04:05"))
@@ -83,7 +88,15 @@ func (m *MySQLGenerator) normalize(t schema.FieldType, value interface{}) string
 			log.Fatalf("unsupported datetime type: %s", t)
 			return ""
 		}
-	case nil:
+	case []byte:
+		return fmt.Sprintf("X'%x'", v)
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			log.Fatalf("failed to marshal %s: %s", t, err)
+		}
+		return fmt.Sprintf("'%s'", b)
+	case nil, schema.Null:
 		return "NULL"
 	default:
 		return fmt.Sprintf("%v", v)
@@ -106,8 +119,12 @@ func (m *MySQLGenerator) insertSQL(md *Metadata, s *schema.Schema) (string, erro
 
 	values := make([]string, len(s.Fields))
 	for {
+		generated, err := s.GenerateRow()
+		if err != nil {
+			return "", err
+		}
 		for i, k := range keys {
-			values[i] = m.normalize(s.Fields[k].Type, s.Fields[k].Generate())
+			values[i] = m.normalize(s.Fields[k], generated[k])
 		}
 		row := m.toRow(keys, values)
 		if err := md.InsertRow(row); err == nil {
@@ -151,9 +168,22 @@ func (m *MySQLGenerator) updateSQL(md *Metadata, s *schema.Schema) (string, erro
 		return "", fmt.Errorf("no more data to update")
 	}
 
+	fields := make([]string, 0, len(newRow))
+	for _, k := range s.Keys() {
+		if _, found := newRow[k]; found && !s.IsPrimaryKey(k) {
+			fields = append(fields, k)
+		}
+	}
+
 	for {
-		for k := range newRow {
-			newRow[k] = m.normalize(s.Fields[k].Type, s.Fields[k].Generate())
+		// Values are generated like those of inserted rows, so that sequences,
+		// snowflakes, foreign keys and monotonic datetimes keep their state.
+		generated, err := s.Anonymize(map[string]interface{}{}, fields)
+		if err != nil {
+			return "", fmt.Errorf("generate fields (%s): %s", strings.Join(fields, ", "), err)
+		}
+		for _, k := range fields {
+			newRow[k] = m.normalize(s.Fields[k], generated[k])
 		}
 
 		for _, k := range s.PrimaryKeys {
@@ -171,8 +201,9 @@ func (m *MySQLGenerator) updateSQL(md *Metadata, s *schema.Schema) (string, erro
 	{
 		sql.WriteString(fmt.Sprintf("UPDATE %s SET ", escapeKey(s.Table)))
 		setFields := make([]string, 0, len(newRow)-len(s.PrimaryKeys))
-		for k, v := range newRow {
-			if s.IsPrimaryKey(k) {
+		for _, k := range s.Keys() {
+			v, found := newRow[k]
+			if !found || s.IsPrimaryKey(k) {
 				continue
 			}
 			setFields = append(setFields, fmt.Sprintf("%s = %s", escapeKey(k), v))
@@ -207,7 +238,7 @@ func (m *MySQLGenerator) deleteSQL(md *Metadata, s *schema.Schema) (string, erro
 		whereClauses := make([]string, len(s.PrimaryKeys))
 		for i, k := range s.PrimaryKeys {
 			v := row[k]
-			whereClauses[i] = fmt.Sprintf("%s = %s", escapeKey(k), m.normalize(s.Fields[k].Type, v))
+			whereClauses[i] = fmt.Sprintf("%s = %s", escapeKey(k), m.normalize(s.Fields[k], v))
 		}
 		sql.WriteString(strings.Join(whereClauses, " AND "))
 		sql.WriteString(";")
@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/luncj/mess/dataset"
+	"github.com/luncj/mess/schema"
+)
+
+// generateSQL generates inserts and then updates of the schema built by build
+// with metadata in a new directory.
+func generateSQL(t *testing.T, build func() (*schema.Schema, error), seed int64, n uint) []string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "mess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, err := Open(s, filepath.Join(dir, "metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataset.Seed(seed)
+	g := NewMySQLGenerator()
+	var sqls []string
+	for _, dml := range []schema.DML{schema.DMLInsert, schema.DMLUpdate} {
+		generated, err := g.Generate(md, s, dml, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqls = append(sqls, generated...)
+	}
+	return sqls
+}
+
+func TestMySQLGeneratorSeeded(t *testing.T) {
+	build := func() (*schema.Schema, error) {
+		return schema.NewBuilder("t").
+			AddInt("id", 1, 1_000_000).
+			PrimaryKey("id").
+			AddString("s", 1, 10).
+			Nullable("s", 30).
+			AddEnum("e", "a", "b", "c").
+			Nullable("e", 50).
+			Build()
+	}
+
+	first, second := generateSQL(t, build, 7, 50), generateSQL(t, build, 7, 50)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("SQL generated with the same seed differs")
+	}
+	if !strings.Contains(strings.Join(first, "\n"), "NULL") {
+		t.Fatal("got no NULL values")
+	}
+}
+
+func TestMySQLGeneratorUpdateStateful(t *testing.T) {
+	build := func() (*schema.Schema, error) {
+		at := schema.Field{Type: schema.FieldTypeDateTime}
+		at.DateTime.Min = "2020-01-01T00:00:00Z"
+		at.DateTime.Monotonic = true
+		at.DateTime.Interval = "1m"
+		return schema.NewBuilder("t").
+			AddInt("id", 1, 1_000_000).
+			PrimaryKey("id").
+			AddField("n", schema.Field{Type: schema.FieldTypeSequence}).
+			AddField("at", at).
+			Build()
+	}
+
+	lastN := int64(20)
+	var lastAt string
+	for _, sql := range generateSQL(t, build, 7, 20)[20:] {
+		if i := strings.Index(sql, "`n` = "); i >= 0 {
+			v := sql[i+len("`n` = "):]
+			n, err := strconv.ParseInt(v[:strings.IndexAny(v, ", ")], 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n <= lastN {
+				t.Fatalf("sequence %d updated after %d does not go on counting", n, lastN)
+			}
+			lastN = n
+		}
+		if i := strings.Index(sql, "`at` = '"); i >= 0 {
+			at := sql[i+len("`at` = '") : i+len("`at` = '2006-01-02 15:04:05")]
+			if at <= lastAt {
+				t.Fatalf("datetime %s updated after %s is not monotonic", at, lastAt)
+			}
+			lastAt = at
+		}
+	}
+	if lastN == 20 {
+		t.Fatal("got no updated sequence values")
+	}
+	if lastAt <= "2020-01-01 00:19:00" {
+		t.Fatalf("got last updated datetime %q, want it after the inserted ones", lastAt)
+	}
+}
@@ -3,10 +3,10 @@ package generator
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"os"
 	"strings"
 
+	"github.com/luncj/mess/dataset"
 	"github.com/luncj/mess/schema"
 )
 
@@ -151,7 +151,7 @@ func (md *Metadata) PickRow() (uint, Row, bool) {
 		return 0, nil, false
 	}
 
-	idx := indexes[rand.Intn(len(indexes))]
+	idx := indexes[dataset.Intn(len(indexes))]
 
 	return idx, md.Rows[idx], true
 }
@@ -3,6 +3,6 @@ module github.com/luncj/mess
 go 1.14
 
 require (
-	github.com/bxcodec/faker/v3 v3.5.0
+	github.com/ghodss/yaml v1.0.0
 	github.com/spf13/cobra v1.0.0
 )
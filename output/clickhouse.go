@@ -0,0 +1,339 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+var maxUint32 = big.NewInt(math.MaxUint32)
+
+var (
+	clickHouseEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"\t", `\t`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\x00", `\0`,
+	)
+	clickHouseQuoter = strings.NewReplacer(
+		`\`, `\\`,
+		"\t", `\t`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\x00", `\0`,
+		`'`, `\'`,
+	)
+)
+
+// ClickHouseWriter writes rows as ClickHouse TabSeparatedWithNames, preceded
+// by a header row of the schema keys. NULL values are written as \N, or [] for
+// arrays, dates as 2006-01-02 and arrays as literals like [1,'a'].
+type ClickHouseWriter struct {
+	// Compression defaults to none.
+	Compression Compression
+	// Include and Exclude select the written columns, all columns of the
+	// schema by default.
+	Include []string
+	Exclude []string
+
+	s    *schema.Schema
+	out  *compressedWriter
+	keys []string
+}
+
+func NewClickHouseWriter(s *schema.Schema, w io.Writer) *ClickHouseWriter {
+	c := &ClickHouseWriter{s: s}
+	c.out = newCompressedWriter(w, &c.Compression)
+	return c
+}
+
+func (c *ClickHouseWriter) WriteRow(row map[string]interface{}) error {
+	if c.keys == nil {
+		keys, err := selectKeys(c.s, c.Include, c.Exclude)
+		if err != nil {
+			return err
+		}
+		header := make([]string, len(keys))
+		for i, k := range keys {
+			header[i] = clickHouseEscaper.Replace(k)
+		}
+		if err := c.writeLine(header); err != nil {
+			return fmt.Errorf("write tsv header: %s", err)
+		}
+		c.keys = keys
+	}
+
+	values := make([]string, len(c.keys))
+	for i, k := range c.keys {
+		if schema.IsNull(row[k]) {
+			values[i] = clickHouseNull(c.s.Fields[k])
+			continue
+		}
+		v, err := clickHouseValue(c.s.Fields[k], row[k])
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	if err := c.writeLine(values); err != nil {
+		return fmt.Errorf("write tsv row: %s", err)
+	}
+	return nil
+}
+
+func (c *ClickHouseWriter) Close() error {
+	return c.out.Close()
+}
+
+func (c *ClickHouseWriter) writeLine(values []string) error {
+	_, err := io.WriteString(c.out, strings.Join(values, "\t")+"\n")
+	return err
+}
+
+// clickHouseNull returns NULL of the field, an empty array for arrays which
+// cannot be nullable.
+func clickHouseNull(f schema.Field) string {
+	if f.Type == schema.FieldTypeArray || f.Type == schema.FieldTypeSet && f.Set.Format == schema.SetFormatJSON {
+		return "[]"
+	}
+	return `\N`
+}
+
+// clickHouseValue renders a non-nil generated value as an escaped
+// TabSeparated value.
+func clickHouseValue(f schema.Field, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case bool:
+		return fmt.Sprint(v), nil
+	case []byte:
+		return clickHouseEscaper.Replace(string(v)), nil
+	case time.Time:
+		return clickHouseTime(f, v), nil
+	case []interface{}:
+		return clickHouseArray(f, v)
+	default:
+		s, err := format(f, v)
+		if err != nil {
+			return "", err
+		}
+		return clickHouseEscaper.Replace(s), nil
+	}
+}
+
+// clickHouseTime formats times in the timezone of their column, which is
+// declared by the CREATE TABLE statement.
+func clickHouseTime(f schema.Field, t time.Time) string {
+	switch f.Type {
+	case schema.FieldTypeDate:
+		return t.Format(dateLayout)
+	case schema.FieldTypeTime:
+		return t.Format(timeLayout)
+	default:
+		return t.Format(datetimeLayout)
+	}
+}
+
+// clickHouseArray renders an array as a ClickHouse literal, the elements of
+// which are generated by the element field of f.
+func clickHouseArray(f schema.Field, elements []interface{}) (string, error) {
+	element := schema.Field{Type: schema.FieldTypeString}
+	if f.Type == schema.FieldTypeArray && f.Array.Element != nil {
+		element = *f.Array.Element
+	}
+
+	literals := make([]string, len(elements))
+	for i, e := range elements {
+		var s string
+		var err error
+		switch v := e.(type) {
+		case nil, schema.Null:
+			s = "NULL"
+		case bool, int64, *big.Int:
+			s = fmt.Sprint(v)
+		case float64:
+			s, err = format(element, v)
+		case []interface{}:
+			s, err = clickHouseArray(element, v)
+		case time.Time:
+			s = fmt.Sprintf("'%s'", clickHouseQuoter.Replace(clickHouseTime(element, v)))
+		case []byte:
+			s = fmt.Sprintf("'%s'", clickHouseQuoter.Replace(string(v)))
+		default:
+			if s, err = format(element, v); err == nil {
+				s = fmt.Sprintf("'%s'", clickHouseQuoter.Replace(s))
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		literals[i] = s
+	}
+	return fmt.Sprintf("[%s]", strings.Join(literals, ",")), nil
+}
+
+// ClickHouseDDL returns the CREATE TABLE statement of the table of the schema
+// for the MergeTree engine, ordered by the primary key. Foreign keys take the
+// column type of the fields they refer to in the parent schemas like DDL.
+func ClickHouseDDL(s *schema.Schema, parents ...*schema.Schema) (string, error) {
+	var definitions []string
+	for _, k := range s.Keys() {
+		typ, err := clickHouseColumnType(referencedField(s.Fields[k], parents))
+		if err != nil {
+			return "", fmt.Errorf("column type of %q: %s", k, err)
+		}
+		definitions = append(definitions, fmt.Sprintf("%s %s", quoteClickHouse(k), typ))
+	}
+
+	keys := make([]string, len(s.PrimaryKeys))
+	for i, pk := range s.PrimaryKeys {
+		keys[i] = quoteClickHouse(pk)
+	}
+
+	ddl := strings.Builder{}
+	{
+		ddl.WriteString(fmt.Sprintf("CREATE TABLE %s (\n  ", quoteClickHouse(s.Table)))
+		ddl.WriteString(strings.Join(definitions, ",\n  "))
+		ddl.WriteString(fmt.Sprintf("\n) ENGINE = MergeTree\nORDER BY (%s);\n", strings.Join(keys, ", ")))
+	}
+
+	return ddl.String(), nil
+}
+
+// clickHouseColumnType maps a field to the column type its generated values
+// fit in, wrapped by Nullable when the field is nullable.
+func clickHouseColumnType(f schema.Field) (string, error) {
+	typ, err := clickHouseType(f)
+	if err != nil {
+		return "", err
+	}
+	// Arrays cannot be nullable in ClickHouse, NULL arrays are written as
+	// empty ones.
+	if f.IsNullable() && !strings.HasPrefix(typ, "Array(") {
+		if strings.HasPrefix(typ, "LowCardinality(") {
+			return fmt.Sprintf("LowCardinality(Nullable(%s))", strings.TrimSuffix(strings.TrimPrefix(typ, "LowCardinality("), ")")), nil
+		}
+		return fmt.Sprintf("Nullable(%s)", typ), nil
+	}
+	return typ, nil
+}
+
+func clickHouseType(f schema.Field) (string, error) {
+	switch f.Type {
+	case schema.FieldTypeInt:
+		return clickHouseIntType(f.IntRange()), nil
+	case schema.FieldTypeSequence, schema.FieldTypeSnowflake, schema.FieldTypeForeignKey:
+		return "Int64", nil
+	case schema.FieldTypeAge:
+		switch max := f.AgeMax(); {
+		case max <= math.MaxUint8:
+			return "UInt8", nil
+		case max <= math.MaxUint16:
+			return "UInt16", nil
+		default:
+			return "UInt32", nil
+		}
+	case schema.FieldTypeFloat, schema.FieldTypeGeo:
+		if f.Type == schema.FieldTypeGeo && f.Geo.Part == schema.GeoPartPoint {
+			return "String", nil
+		}
+		return "Float64", nil
+	case schema.FieldTypeDecimal, schema.FieldTypeMoney, schema.FieldTypePercent:
+		typ, err := DialectMySQL.columnType(f)
+		if err != nil {
+			return "", err
+		}
+		return strings.Replace(typ, "DECIMAL", "Decimal", 1), nil
+	case schema.FieldTypeBool:
+		return "Bool", nil
+	case schema.FieldTypeUUID:
+		return "UUID", nil
+	case schema.FieldTypeEnum, schema.FieldTypeGender, schema.FieldTypeCountry, schema.FieldTypeCurrencyCode:
+		return "LowCardinality(String)", nil
+	case schema.FieldTypeSet:
+		if f.Set.Format == schema.SetFormatJSON {
+			return "Array(String)", nil
+		}
+		return "String", nil
+	case schema.FieldTypeArray:
+		if f.Array.Element == nil {
+			return "", fmt.Errorf("array element is required")
+		}
+		typ, err := clickHouseColumnType(*f.Array.Element)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Array(%s)", typ), nil
+	case schema.FieldTypeDate, schema.FieldTypeDateTime:
+		switch f.TimeFormat() {
+		case "":
+		case schema.TimeFormatEpoch, schema.TimeFormatEpochMillis:
+			return "Int64", nil
+		default:
+			return "String", nil
+		}
+		if f.Type == schema.FieldTypeDate {
+			return "Date32", nil
+		}
+		if f.Location() != nil {
+			return fmt.Sprintf("DateTime64(0, %s)", DialectPostgres.quoteString(f.DateTime.Timezone)), nil
+		}
+		return "DateTime64(0)", nil
+	case schema.FieldTypeDuration:
+		if f.Duration.Unit != "" {
+			return "Int64", nil
+		}
+		return "String", nil
+	case schema.FieldTypeConst:
+		typ, err := DialectPostgres.columnType(f)
+		if err != nil {
+			return "", err
+		}
+		switch typ {
+		case "BOOLEAN":
+			return "Bool", nil
+		case "BIGINT":
+			return "Int64", nil
+		case "DOUBLE PRECISION":
+			return "Float64", nil
+		default:
+			return "String", nil
+		}
+	}
+
+	// The remaining types are generated as text, or bytes of binary fields.
+	if _, err := DialectMySQL.columnType(f); err != nil {
+		return "", err
+	}
+	return "String", nil
+}
+
+// clickHouseIntType returns the smallest of the 32, 64, 128 and 256 bits
+// integer types holding [min, max].
+func clickHouseIntType(min, max *big.Int) string {
+	switch {
+	case min == nil || max == nil:
+		return "Int64"
+	case min.Sign() >= 0 && max.Cmp(maxUint32) <= 0:
+		return "UInt32"
+	case min.Cmp(minInt32) >= 0 && max.Cmp(maxInt32) <= 0:
+		return "Int32"
+	case min.IsInt64() && max.IsInt64():
+		return "Int64"
+	case min.Sign() >= 0 && max.Cmp(maxUint64) <= 0:
+		return "UInt64"
+	case min.BitLen() < 128 && max.BitLen() < 128:
+		return "Int128"
+	default:
+		return "Int256"
+	}
+}
+
+func quoteClickHouse(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "\\`"))
+}
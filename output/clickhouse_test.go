@@ -0,0 +1,20 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/luncj/mess/schema"
+)
+
+func TestClickHouseArray(t *testing.T) {
+	f := schema.Field{Type: schema.FieldTypeArray}
+	f.Array.Element = &schema.Field{Type: schema.FieldTypeFloat}
+	f.Array.Element.Float.Scale = 2
+	got, err := clickHouseArray(f, []interface{}{1e12, 0.5, schema.Null{}, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[1000000000000.00,0.50,NULL,NULL]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+type Compression string
+
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+)
+
+func (c Compression) String() string {
+	return string(c)
+}
+
+func CompressionFromString(s string) (Compression, error) {
+	switch s {
+	case CompressionNone.String():
+		return CompressionNone, nil
+	case CompressionGzip.String():
+		return CompressionGzip, nil
+	default:
+		return "", fmt.Errorf("invalid compression %q, required (%q / %q)", s, CompressionNone, CompressionGzip)
+	}
+}
+
+// compressedWriter compresses what a Writer writes with the compression the
+// Writer is set to when it first writes.
+type compressedWriter struct {
+	w           io.Writer
+	compression *Compression
+	gz          *gzip.Writer
+	opened      bool
+}
+
+func newCompressedWriter(w io.Writer, compression *Compression) *compressedWriter {
+	return &compressedWriter{
+		w:           w,
+		compression: compression,
+	}
+}
+
+func (c *compressedWriter) open() error {
+	if c.opened {
+		return nil
+	}
+	c.opened = true
+
+	switch *c.compression {
+	case "", CompressionNone:
+	case CompressionGzip:
+		c.gz = gzip.NewWriter(c.w)
+	default:
+		_, err := CompressionFromString(c.compression.String())
+		return err
+	}
+	return nil
+}
+
+func (c *compressedWriter) Write(b []byte) (int, error) {
+	if err := c.open(); err != nil {
+		return 0, err
+	}
+	if c.gz != nil {
+		return c.gz.Write(b)
+	}
+	return c.w.Write(b)
+}
+
+// Close flushes the compressed data, it does not close the underlying writer.
+func (c *compressedWriter) Close() error {
+	if err := c.open(); err != nil {
+		return err
+	}
+	if c.gz == nil {
+		return nil
+	}
+	if err := c.gz.Close(); err != nil {
+		return fmt.Errorf("close %s: %s", *c.compression, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/luncj/mess/schema"
+)
+
+// CSVWriter writes rows as RFC 4180 CSV, preceded by a header row of the
+// schema keys.
+type CSVWriter struct {
+	// Null is written for NULL values, e.g. `\N`. It is empty by default, so
+	// NULL values and empty strings are written alike unless it is set.
+	Null string
+	// Compression defaults to none.
+	Compression Compression
+	// Include and Exclude select the written columns, all columns of the
+	// schema by default.
+	Include []string
+	Exclude []string
+
+	s      *schema.Schema
+	out    *compressedWriter
+	w      *csv.Writer
+	keys   []string
+	header bool
+}
+
+func NewCSVWriter(s *schema.Schema, w io.Writer) *CSVWriter {
+	c := &CSVWriter{s: s}
+	c.out = newCompressedWriter(w, &c.Compression)
+	c.w = csv.NewWriter(c.out)
+	return c
+}
+
+func (c *CSVWriter) WriteRow(row map[string]interface{}) error {
+	if !c.header {
+		keys, err := selectKeys(c.s, c.Include, c.Exclude)
+		if err != nil {
+			return err
+		}
+		if err := c.w.Write(keys); err != nil {
+			return fmt.Errorf("write csv header: %s", err)
+		}
+		c.keys = keys
+		c.header = true
+	}
+	keys := c.keys
+
+	record := make([]string, len(keys))
+	for i, k := range keys {
+		v := row[k]
+		if schema.IsNull(v) {
+			record[i] = c.Null
+			continue
+		}
+
+		s, err := format(c.s.Fields[k], v)
+		if err != nil {
+			return err
+		}
+		record[i] = s
+	}
+
+	if err := c.w.Write(record); err != nil {
+		return fmt.Errorf("write csv row: %s", err)
+	}
+	return nil
+}
+
+func (c *CSVWriter) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.out.Close()
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/luncj/mess/schema"
+)
+
+// emptyStringSchema generates rows of which s is either NULL or an empty
+// string.
+func emptyStringSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	s, err := schema.NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddString("s", 0, 1).
+		Nullable("s", 50).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCSVWriterNullAndEmptyString(t *testing.T) {
+	s := emptyStringSchema(t)
+	rows, err := s.GenerateRows(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(s, &buf)
+	w.Null = `\N`
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(rows)+1 {
+		t.Fatalf("got %d lines, want %d", len(lines), len(rows)+1)
+	}
+	nulls := 0
+	for i, row := range rows {
+		want := ""
+		if schema.IsNull(row["s"]) {
+			want = `\N`
+			nulls++
+		}
+		if got := strings.SplitN(lines[i+1], ",", 2)[1]; got != want {
+			t.Errorf("row %d: got s = %q, want %q", i, got, want)
+		}
+	}
+	if nulls == 0 || nulls == len(rows) {
+		t.Fatalf("got %d NULL values of %d rows, want both NULL values and empty strings", nulls, len(rows))
+	}
+}
+
+func TestCSVWriterDefaultNull(t *testing.T) {
+	s := emptyStringSchema(t)
+
+	var buf bytes.Buffer
+	w := NewCSVWriter(s, &buf)
+	for _, row := range []map[string]interface{}{{"id": int64(1), "s": schema.Null{}}, {"id": int64(2), "s": ""}} {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "id,s\n1,\n2,\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
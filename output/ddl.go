@@ -0,0 +1,451 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/luncj/mess/dataset"
+	"github.com/luncj/mess/schema"
+)
+
+var (
+	maxInt32  = big.NewInt(math.MaxInt32)
+	minInt32  = big.NewInt(math.MinInt32)
+	maxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// DDL returns the CREATE TABLE statement of the table of the schema. Foreign
+// keys take the column type of the fields they refer to in the parent schemas,
+// they are assumed to refer to integer primary keys of the tables which are
+// not given.
+func DDL(s *schema.Schema, dialect string, parents ...*schema.Schema) (string, error) {
+	d, err := DialectFromString(dialect)
+	if err != nil {
+		return "", err
+	}
+
+	var definitions []string
+	for _, k := range s.Keys() {
+		f := s.Fields[k]
+		typ, err := d.fieldType(s, k, referencedField(f, parents))
+		if err != nil {
+			return "", fmt.Errorf("column type of %q: %s", k, err)
+		}
+		column := fmt.Sprintf("%s %s", d.quoteIdentifier(k), typ)
+		if !f.IsNullable() {
+			column += " NOT NULL"
+		}
+		definitions = append(definitions, column)
+	}
+
+	definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", d.quoteIdentifiers(s.PrimaryKeys)))
+	for _, uk := range s.UniqueKeys {
+		definitions = append(definitions, fmt.Sprintf("UNIQUE (%s)", d.quoteIdentifiers(uk)))
+	}
+
+	ddl := strings.Builder{}
+	{
+		ddl.WriteString(fmt.Sprintf("CREATE TABLE %s (\n  ", d.quoteIdentifier(s.Table)))
+		ddl.WriteString(strings.Join(definitions, ",\n  "))
+		ddl.WriteString("\n);\n")
+	}
+
+	return ddl.String(), nil
+}
+
+// referencedField returns the field a foreign key refers to in the parent
+// schemas, following the foreign keys referring to foreign keys, with the
+// nullable rate and default value of f. It returns f when f is not a foreign key or the field is
+// not found.
+func referencedField(f schema.Field, parents []*schema.Schema) schema.Field {
+	ref := f
+	// A chain of foreign keys is at most as long as the parents, unless it is
+	// a cycle.
+	for i := 0; ref.Type == schema.FieldTypeForeignKey && i < len(parents); i++ {
+		var found bool
+		for _, p := range parents {
+			if p.Table != ref.ForeignKey.Table {
+				continue
+			}
+			ref, found = p.Fields[ref.ForeignKey.Field]
+			break
+		}
+		if !found {
+			return f
+		}
+	}
+	if ref.Type == schema.FieldTypeForeignKey {
+		return f
+	}
+	ref.NullableRate, ref.Default, ref.UseDefaultRate = f.NullableRate, f.Default, f.UseDefaultRate
+	return ref
+}
+
+// mysqlMaxKeyLength is the length in characters of the longest VARCHAR column
+// InnoDB indexes, 3072 bytes of utf8mb4.
+const mysqlMaxKeyLength = 768
+
+// fieldType returns the column type of f, the field k of s. Templates are
+// sized from the fields they refer to, and MySQL keys which would be TEXT or
+// JSON columns are VARCHAR columns of the length of their longest values, as
+// MySQL does not index TEXT and JSON columns.
+func (d Dialect) fieldType(s *schema.Schema, k string, f schema.Field) (string, error) {
+	typ, err := d.columnType(f)
+	if err != nil {
+		return "", err
+	}
+	key := d == DialectMySQL && isKey(s, k)
+	if typ != "TEXT" && !(key && (typ == "JSON" || typ == "BLOB")) {
+		return typ, nil
+	}
+	n, bounded := maxLength(f, s.Fields)
+	switch {
+	case key && !bounded:
+		return "", fmt.Errorf("%s field of unbounded length cannot be a MySQL key", f.Type)
+	case key && n > mysqlMaxKeyLength:
+		return "", fmt.Errorf("%s field of up to %d characters cannot be a MySQL key, longer than %d characters", f.Type, n, mysqlMaxKeyLength)
+	case key:
+		return fmt.Sprintf("VARCHAR(%d)", n), nil
+	case bounded && f.Type == schema.FieldTypeTemplate:
+		return d.pick(fmt.Sprintf("VARCHAR(%d)", n), "TEXT", "TEXT"), nil
+	default:
+		return typ, nil
+	}
+}
+
+// isKey reports whether k is in the primary key or a unique key of s.
+func isKey(s *schema.Schema, k string) bool {
+	for _, pk := range s.PrimaryKeys {
+		if pk == k {
+			return true
+		}
+	}
+	for _, uk := range s.UniqueKeys {
+		for _, key := range uk {
+			if key == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maxLength returns the length in characters of the longest value of f once
+// written as text, the fields of the schema sizing the templates. It returns
+// false when the length is unbounded or unknown.
+func maxLength(f schema.Field, fields map[string]schema.Field) (int, bool) {
+	switch f.Type {
+	case schema.FieldTypeString:
+		str := f.String
+		switch str.Type {
+		case schema.StringTypeWord:
+			return stringLength(f, str.Word.Num*dataset.MaxWordLength()+2*(str.Word.Num-1)), true
+		case schema.StringTypeSentence:
+			return stringLength(f, str.Sentence.Num*(dataset.MaxSentenceLength()+1)-1), true
+		case schema.StringTypeParagraph:
+			return stringLength(f, str.Paragraph.Num*(dataset.MaxParagraphLength()+1)-1), true
+		}
+	case schema.FieldTypeConst:
+		var v string
+		if err := json.Unmarshal(f.Const.Value, &v); err == nil {
+			return utf8.RuneCountInString(v), true
+		}
+	case schema.FieldTypeTemplate:
+		// The placeholders {k} are replaced by the values of the fields k.
+		n := utf8.RuneCountInString(f.Template.Expr)
+		for _, k := range f.Dependencies() {
+			dep, found := fields[k]
+			if !found {
+				return 0, false
+			}
+			l, bounded := maxLength(dep, fields)
+			if !bounded {
+				return 0, false
+			}
+			n += l - utf8.RuneCountInString(k) - len("{}")
+		}
+		return n, true
+	case schema.FieldTypeInt:
+		min, max := f.IntRange()
+		if min == nil || max == nil {
+			return 0, false
+		}
+		n := len(min.String())
+		if l := len(max.String()); l > n {
+			n = l
+		}
+		return n, true
+	case schema.FieldTypeSequence, schema.FieldTypeSnowflake, schema.FieldTypeForeignKey:
+		return len(strconv.FormatInt(math.MinInt64, 10)), true
+	case schema.FieldTypeEnum:
+		var n int
+		for _, o := range f.Enum.Options {
+			if l := utf8.RuneCountInString(o); l > n {
+				n = l
+			}
+		}
+		return n, true
+	case schema.FieldTypeBinary:
+		switch f.Binary.Encoding {
+		case schema.BinaryEncodingHex:
+			return 2 * f.Binary.MaxLength, true
+		case schema.BinaryEncodingBase64:
+			return (f.Binary.MaxLength + 2) / 3 * 4, true
+		}
+		return 0, false
+	}
+
+	// The length of the other fields is that of their MySQL column.
+	typ, err := DialectMySQL.columnType(f)
+	if err != nil {
+		return 0, false
+	}
+	for _, prefix := range []string{"VARCHAR(", "CHAR("} {
+		if strings.HasPrefix(typ, prefix) {
+			n, err := strconv.Atoi(typ[len(prefix):strings.Index(typ, ")")])
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+func (d Dialect) quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.quoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// columnType maps a field to the column type its generated values fit in.
+func (d Dialect) columnType(f schema.Field) (string, error) {
+	switch f.Type {
+	case schema.FieldTypeInt:
+		return d.intType(f.IntRange()), nil
+	case schema.FieldTypeGender:
+		if d == DialectMySQL {
+			return fmt.Sprintf("ENUM(%s)", d.quoteStrings([]string{dataset.GenderMale, dataset.GenderFemale, dataset.GenderOther})), nil
+		}
+		return "TEXT", nil
+	case schema.FieldTypeAge:
+		if max := f.AgeMax(); max > math.MaxInt16 {
+			return d.intType(big.NewInt(int64(f.Age.Min)), big.NewInt(int64(max))), nil
+		}
+		return d.pick("SMALLINT", "SMALLINT", "INTEGER"), nil
+	case schema.FieldTypeDuration:
+		if f.Duration.Unit != "" {
+			return d.pick("BIGINT", "BIGINT", "INTEGER"), nil
+		}
+		return d.pick("VARCHAR(32)", "VARCHAR(32)", "TEXT"), nil
+	case schema.FieldTypeSequence, schema.FieldTypeSnowflake, schema.FieldTypeForeignKey:
+		return d.pick("BIGINT", "BIGINT", "INTEGER"), nil
+	case schema.FieldTypeFloat:
+		return d.pick("DOUBLE", "DOUBLE PRECISION", "REAL"), nil
+	case schema.FieldTypeMoney:
+		m := f.Money
+		digits := len(strings.TrimPrefix(strconv.FormatInt(m.Min, 10), "-"))
+		if n := len(strings.TrimPrefix(strconv.FormatInt(m.Max, 10), "-")); n > digits {
+			digits = n
+		}
+		if digits <= m.Scale {
+			digits = m.Scale + 1
+		}
+		return d.pick(
+			fmt.Sprintf("DECIMAL(%d,%d)", digits, m.Scale),
+			fmt.Sprintf("NUMERIC(%d,%d)", digits, m.Scale),
+			"NUMERIC",
+		), nil
+	case schema.FieldTypePercent:
+		digits := 3
+		if f.Percent.AsFraction {
+			digits = 1
+		}
+		sc := f.Percent.Scale
+		return d.pick(
+			fmt.Sprintf("DECIMAL(%d,%d)", digits+sc, sc),
+			fmt.Sprintf("NUMERIC(%d,%d)", digits+sc, sc),
+			"NUMERIC",
+		), nil
+	case schema.FieldTypeDecimal:
+		dec := f.Decimal
+		digits := len(new(big.Int).Abs(dec.Min).String())
+		if n := len(new(big.Int).Abs(dec.Max).String()); n > digits {
+			digits = n
+		}
+		return d.pick(
+			fmt.Sprintf("DECIMAL(%d,%d)", digits+dec.Scale, dec.Scale),
+			fmt.Sprintf("NUMERIC(%d,%d)", digits+dec.Scale, dec.Scale),
+			"NUMERIC",
+		), nil
+	case schema.FieldTypeString:
+		if f.String.Type == schema.StringTypeAscii {
+			n := stringLength(f, f.String.Ascii.MaxLength)
+			return d.pick(fmt.Sprintf("VARCHAR(%d)", n), fmt.Sprintf("VARCHAR(%d)", n), "TEXT"), nil
+		}
+		if f.String.Type == schema.StringTypeUnicode {
+			n := stringLength(f, f.String.Unicode.MaxLength)
+			return d.pick(fmt.Sprintf("VARCHAR(%d) CHARACTER SET utf8mb4", n), fmt.Sprintf("VARCHAR(%d)", n), "TEXT"), nil
+		}
+		return "TEXT", nil
+	case schema.FieldTypeEnum:
+		if d == DialectMySQL {
+			return fmt.Sprintf("ENUM(%s)", d.quoteStrings(f.Enum.Options)), nil
+		}
+		return "TEXT", nil
+	case schema.FieldTypeSet:
+		if f.Set.Format == schema.SetFormatJSON {
+			return d.pick("JSON", "JSONB", "TEXT"), nil
+		}
+		if d == DialectMySQL {
+			return fmt.Sprintf("SET(%s)", d.quoteStrings(f.Set.Options)), nil
+		}
+		return "TEXT", nil
+	case schema.FieldTypeBool:
+		return d.pick("TINYINT(1)", "BOOLEAN", "INTEGER"), nil
+	case schema.FieldTypeUUID:
+		return d.pick("CHAR(36)", "UUID", "TEXT"), nil
+	case schema.FieldTypeHex:
+		n := f.Hex.Length
+		return d.pick(fmt.Sprintf("CHAR(%d)", n), fmt.Sprintf("CHAR(%d)", n), "TEXT"), nil
+	case schema.FieldTypeBase64:
+		n := (f.Base64.MaxBytes + 2) / 3 * 4
+		return d.pick(fmt.Sprintf("VARCHAR(%d)", n), fmt.Sprintf("VARCHAR(%d)", n), "TEXT"), nil
+	case schema.FieldTypeUsername:
+		if n := f.Username.MaxLength; n > 0 {
+			return d.pick(fmt.Sprintf("VARCHAR(%d)", n), fmt.Sprintf("VARCHAR(%d)", n), "TEXT"), nil
+		}
+		return d.pick("VARCHAR(255)", "TEXT", "TEXT"), nil
+	case schema.FieldTypeCurrencyCode:
+		return d.pick("CHAR(3)", "CHAR(3)", "TEXT"), nil
+	case schema.FieldTypeCountry:
+		if f.Country.Format == dataset.CountryFormatAlpha3 || f.Country.Format == dataset.CountryFormatNumeric {
+			return d.pick("CHAR(3)", "CHAR(3)", "TEXT"), nil
+		}
+		return d.pick("CHAR(2)", "CHAR(2)", "TEXT"), nil
+	case schema.FieldTypeULID:
+		return d.pick("CHAR(26)", "CHAR(26)", "TEXT"), nil
+	case schema.FieldTypeDate, schema.FieldTypeDateTime, schema.FieldTypeTime:
+		switch f.TimeFormat() {
+		case "":
+		case schema.TimeFormatEpoch, schema.TimeFormatEpochMillis:
+			return d.pick("BIGINT", "BIGINT", "INTEGER"), nil
+		default:
+			return d.pick("VARCHAR(64)", "VARCHAR(64)", "TEXT"), nil
+		}
+		switch {
+		case d == DialectSQLite:
+			return "TEXT", nil
+		case f.Type == schema.FieldTypeDate:
+			return "DATE", nil
+		case f.Type == schema.FieldTypeTime:
+			return "TIME", nil
+		case d == DialectPostgres && f.Location() != nil:
+			return "TIMESTAMP WITH TIME ZONE", nil
+		default:
+			return d.pick("DATETIME", "TIMESTAMP", "TEXT"), nil
+		}
+	case schema.FieldTypeArray:
+		// Arrays are written as array literals for PostgreSQL, as JSON for
+		// the others.
+		if d == DialectPostgres && f.Array.Element != nil {
+			typ, err := d.columnType(*f.Array.Element)
+			if err != nil {
+				return "", err
+			}
+			return typ + "[]", nil
+		}
+		return d.pick("JSON", "JSONB", "TEXT"), nil
+	case schema.FieldTypeJSON, schema.FieldTypeObject:
+		return d.pick("JSON", "JSONB", "TEXT"), nil
+	case schema.FieldTypeBinary:
+		if f.Binary.Encoding == "" || f.Binary.Encoding == schema.BinaryEncodingRaw {
+			return d.pick("BLOB", "BYTEA", "BLOB"), nil
+		}
+		return "TEXT", nil
+	case schema.FieldTypeGeo:
+		if f.Geo.Part != schema.GeoPartPoint {
+			return d.pick("DOUBLE", "DOUBLE PRECISION", "REAL"), nil
+		}
+		return d.pick("VARCHAR(64)", "VARCHAR(64)", "TEXT"), nil
+	case schema.FieldTypeConst:
+		var v interface{}
+		if err := json.Unmarshal(f.Const.Value, &v); err != nil {
+			return "", fmt.Errorf("decode const value: %s", err)
+		}
+		switch v := v.(type) {
+		case bool:
+			return d.pick("TINYINT(1)", "BOOLEAN", "INTEGER"), nil
+		case float64:
+			if v == math.Trunc(v) {
+				return d.pick("BIGINT", "BIGINT", "INTEGER"), nil
+			}
+			return d.pick("DOUBLE", "DOUBLE PRECISION", "REAL"), nil
+		case string:
+			return d.pick(fmt.Sprintf("VARCHAR(%d)", utf8.RuneCountInString(v)), "TEXT", "TEXT"), nil
+		default:
+			return d.pick("JSON", "JSONB", "TEXT"), nil
+		}
+	case schema.FieldTypeEmail, schema.FieldTypePhone, schema.FieldTypeSlug, schema.FieldTypeName, schema.FieldTypeAddress,
+		schema.FieldTypePath, schema.FieldTypeCompany, schema.FieldTypeURL, schema.FieldTypeIPv4, schema.FieldTypeIPv6, schema.FieldTypeMAC,
+		schema.FieldTypeColor, schema.FieldTypeRegex:
+		return d.pick("VARCHAR(255)", "TEXT", "TEXT"), nil
+	case schema.FieldTypeTemplate:
+		// Templates are sized from the fields they refer to by DDL.
+		return "TEXT", nil
+	}
+	return "", fmt.Errorf("unsupported field type %q", f.Type)
+}
+
+// stringLength returns the length of strings of at most max characters once
+// padded and affixed.
+func stringLength(f schema.Field, max int) int {
+	if f.String.PadTo > max {
+		max = f.String.PadTo
+	}
+	return max + utf8.RuneCountInString(f.String.Prefix) + utf8.RuneCountInString(f.String.Suffix)
+}
+
+// intType returns the smallest integer type holding [min, max].
+func (d Dialect) intType(min, max *big.Int) string {
+	switch {
+	case min == nil || max == nil:
+		return d.pick("BIGINT", "BIGINT", "INTEGER")
+	case min.Cmp(minInt32) >= 0 && max.Cmp(maxInt32) <= 0:
+		return d.pick("INT", "INTEGER", "INTEGER")
+	case min.IsInt64() && max.IsInt64():
+		return d.pick("BIGINT", "BIGINT", "INTEGER")
+	case min.Sign() >= 0 && max.Cmp(maxUint64) <= 0:
+		return d.pick("BIGINT UNSIGNED", "NUMERIC(20)", "NUMERIC")
+	default:
+		digits := len(new(big.Int).Abs(min).String())
+		if n := len(new(big.Int).Abs(max).String()); n > digits {
+			digits = n
+		}
+		return d.pick(fmt.Sprintf("DECIMAL(%d,0)", digits), fmt.Sprintf("NUMERIC(%d)", digits), "NUMERIC")
+	}
+}
+
+// pick returns the type of the dialect.
+func (d Dialect) pick(mysql, postgres, sqlite string) string {
+	switch d {
+	case DialectPostgres:
+		return postgres
+	case DialectSQLite:
+		return sqlite
+	default:
+		return mysql
+	}
+}
+
+func (d Dialect) quoteStrings(options []string) string {
+	quoted := make([]string, len(options))
+	for i, o := range options {
+		quoted[i] = d.quoteString(o)
+	}
+	return strings.Join(quoted, ",")
+}
@@ -0,0 +1,248 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/luncj/mess/dataset"
+	"github.com/luncj/mess/schema"
+)
+
+func foreignKey(table, field string) schema.Field {
+	f := schema.Field{Type: schema.FieldTypeForeignKey}
+	f.ForeignKey.Table = table
+	f.ForeignKey.Field = field
+	return f
+}
+
+func TestDDLForeignKeyType(t *testing.T) {
+	users, err := schema.NewBuilder("users").AddUUID("id").PrimaryKey("id").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// profiles refers to users by its primary key, which settings refers to.
+	profiles, err := schema.NewBuilder("profiles").
+		AddField("user_id", foreignKey("users", "id")).
+		PrimaryKey("user_id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := schema.NewBuilder("settings").
+		AddInt("id", 1, 100).
+		PrimaryKey("id").
+		AddField("profile_id", foreignKey("profiles", "user_id")).
+		Nullable("profile_id", 10).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ddl  func() (string, error)
+		want string
+	}{
+		{"postgres", func() (string, error) {
+			return DDL(settings, DialectPostgres.String(), users, profiles)
+		}, `"profile_id" UUID,`},
+		{"mysql", func() (string, error) {
+			return DDL(profiles, DialectMySQL.String(), users)
+		}, "`user_id` CHAR(36) NOT NULL,"},
+		{"unknown parent", func() (string, error) {
+			return DDL(settings, DialectPostgres.String(), users)
+		}, `"profile_id" BIGINT,`},
+		{"clickhouse", func() (string, error) {
+			return ClickHouseDDL(settings, users, profiles)
+		}, "`profile_id` Nullable(UUID)"},
+	}
+	for _, test := range tests {
+		ddl, err := test.ddl()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(ddl, test.want) {
+			t.Errorf("%s: got DDL %s, want it to contain %s", test.name, ddl, test.want)
+		}
+	}
+}
+
+func TestDDLMySQLTextKeys(t *testing.T) {
+	word := schema.Field{Type: schema.FieldTypeString}
+	word.String.Type = schema.StringTypeWord
+	word.String.Word.Num = 2
+	sentence := schema.Field{Type: schema.FieldTypeString}
+	sentence.String.Type = schema.StringTypeSentence
+	sentence.String.Sentence.Num = 1
+	sentence.String.Prefix = "s-"
+	login := schema.Field{Type: schema.FieldTypeTemplate}
+	login.Template.Expr = "user-{id}"
+	kind := schema.Field{Type: schema.FieldTypeConst}
+	kind.Const.Value = json.RawMessage(`"abc"`)
+	s, err := schema.NewBuilder("t").
+		AddInt("id", 1, 100).
+		AddField("word", word).
+		PrimaryKey("id", "word").
+		AddField("sentence", sentence).
+		UniqueKey("sentence").
+		AddField("login", login).
+		AddField("kind", kind).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ddl, err := DDL(s, DialectMySQL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wordLength := 2*dataset.MaxWordLength() + len(", ")
+	sentenceLength := dataset.MaxSentenceLength() + len("s-")
+	for _, want := range []string{
+		fmt.Sprintf("`word` VARCHAR(%d) NOT NULL,", wordLength),
+		fmt.Sprintf("`sentence` VARCHAR(%d) NOT NULL,", sentenceLength),
+		"`login` VARCHAR(8) NOT NULL,",
+		"`kind` VARCHAR(3) NOT NULL,",
+	} {
+		if !strings.Contains(ddl, want) {
+			t.Errorf("got DDL %s, want it to contain %s", ddl, want)
+		}
+	}
+
+	rows, err := s.GenerateRows(200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if n := utf8.RuneCountInString(row["word"].(string)); n > wordLength {
+			t.Errorf("got word %q longer than %d", row["word"], wordLength)
+		}
+		if n := utf8.RuneCountInString(row["sentence"].(string)); n > sentenceLength {
+			t.Errorf("got sentence %q longer than %d", row["sentence"], sentenceLength)
+		}
+	}
+}
+
+func TestDDLMySQLUnboundedKey(t *testing.T) {
+	s, err := schema.NewBuilder("t").
+		AddInt("id", 1, 100).
+		PrimaryKey("id").
+		AddField("doc", schema.Field{Type: schema.FieldTypeJSON}).
+		UniqueKey("doc").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DDL(s, DialectMySQL.String()); err == nil {
+		t.Error("got no error for a JSON MySQL key")
+	}
+	if _, err := DDL(s, DialectPostgres.String()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDDLUnsignedTemplate(t *testing.T) {
+	n := schema.Field{Type: schema.FieldTypeInt}
+	n.Int.Min, n.Int.Max, n.Int.Unsigned = big.NewInt(-100000), big.NewInt(99), true
+	code := schema.Field{Type: schema.FieldTypeTemplate}
+	code.Template.Expr = "u{n}"
+	s, err := schema.NewBuilder("t").
+		AddInt("id", 1, 100).
+		PrimaryKey("id").
+		AddField("n", n).
+		AddField("code", code).
+		UniqueKey("code").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ddl, err := DDL(s, DialectMySQL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "`code` VARCHAR(3) NOT NULL,"; !strings.Contains(ddl, want) {
+		t.Errorf("got DDL %s, want it to contain %s", ddl, want)
+	}
+}
+
+func TestDDLSnowflakeTemplate(t *testing.T) {
+	order := schema.Field{Type: schema.FieldTypeTemplate}
+	order.Template.Expr = "o-{id}"
+	s, err := schema.NewBuilder("t").
+		AddField("id", schema.Field{Type: schema.FieldTypeSnowflake}).
+		PrimaryKey("id").
+		AddField("order", order).
+		UniqueKey("order").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ddl, err := DDL(s, DialectMySQL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "`order` VARCHAR(22) NOT NULL,"; !strings.Contains(ddl, want) {
+		t.Errorf("got DDL %s, want it to contain %s", ddl, want)
+	}
+}
+
+func TestDDLNullDefault(t *testing.T) {
+	f := schema.Field{Type: schema.FieldTypeString, Default: json.RawMessage(`null`), UseDefaultRate: 10}
+	f.String.Ascii.MaxLength = 10
+	s, err := schema.NewBuilder("t").
+		AddInt("id", 1, 100).
+		PrimaryKey("id").
+		AddField("s", f).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ddl, err := DDL(s, DialectPostgres.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ddl, `"s" TEXT,`) {
+		t.Errorf("got DDL %s, want s nullable", ddl)
+	}
+}
+
+func TestDDLAgeType(t *testing.T) {
+	tests := []struct {
+		max               int
+		mysql, clickHouse string
+	}{
+		{0, "SMALLINT", "UInt8"},
+		{255, "SMALLINT", "UInt8"},
+		{300, "SMALLINT", "UInt16"},
+		{100_000, "INT", "UInt32"},
+	}
+	for _, test := range tests {
+		age := schema.Field{Type: schema.FieldTypeAge}
+		age.Age.Max = test.max
+		s, err := schema.NewBuilder("t").AddField("age", age).PrimaryKey("age").Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ddl, err := DDL(s, DialectMySQL.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("`age` %s NOT NULL", test.mysql); !strings.Contains(ddl, want) {
+			t.Errorf("max %d: got DDL %s, want it to contain %s", test.max, ddl, want)
+		}
+		if ddl, err = ClickHouseDDL(s); err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("`age` %s", test.clickHouse); !strings.Contains(ddl, want) {
+			t.Errorf("max %d: got DDL %s, want it to contain %s", test.max, ddl, want)
+		}
+	}
+}
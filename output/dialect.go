@@ -0,0 +1,118 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/luncj/mess/schema"
+)
+
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+func (d Dialect) String() string {
+	return string(d)
+}
+
+func DialectFromString(s string) (Dialect, error) {
+	switch s {
+	case DialectMySQL.String():
+		return DialectMySQL, nil
+	case DialectPostgres.String():
+		return DialectPostgres, nil
+	case DialectSQLite.String():
+		return DialectSQLite, nil
+	default:
+		return "", fmt.Errorf("invalid dialect %q, required (%q / %q / %q)", s, DialectMySQL, DialectPostgres, DialectSQLite)
+	}
+}
+
+func (d Dialect) quoteIdentifier(name string) string {
+	switch d {
+	case DialectPostgres, DialectSQLite:
+		return fmt.Sprintf(`"%s"`, strings.ReplaceAll(name, `"`, `""`))
+	default:
+		return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+	}
+}
+
+var mysqlEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x1a", `\Z`,
+)
+
+func (d Dialect) quoteString(s string) string {
+	switch d {
+	case DialectPostgres, DialectSQLite:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+	default:
+		return fmt.Sprintf("'%s'", mysqlEscaper.Replace(s))
+	}
+}
+
+func (d Dialect) bool(b bool) string {
+	switch {
+	case d == DialectPostgres && b:
+		return "true"
+	case d == DialectPostgres:
+		return "false"
+	case b:
+		return "1"
+	default:
+		return "0"
+	}
+}
+
+func (d Dialect) binary(b []byte) string {
+	switch d {
+	case DialectPostgres:
+		return fmt.Sprintf(`'\x%x'`, b)
+	default:
+		return fmt.Sprintf("X'%x'", b)
+	}
+}
+
+var postgresArrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// PostgresArray renders an array as a PostgreSQL array literal like
+// {"1","a,b",NULL}, the elements of which are generated by the element field
+// of f. Elements are double quoted so that commas, braces and spaces within
+// them are kept, nested arrays are not.
+func PostgresArray(f schema.Field, elements []interface{}) (string, error) {
+	element := schema.Field{Type: schema.FieldTypeString}
+	if f.Type == schema.FieldTypeArray && f.Array.Element != nil {
+		element = *f.Array.Element
+	}
+
+	literals := make([]string, len(elements))
+	for i, e := range elements {
+		var s string
+		var err error
+		switch v := e.(type) {
+		case nil, schema.Null:
+			s = "NULL"
+		case []interface{}:
+			s, err = PostgresArray(element, v)
+		case []byte:
+			s = fmt.Sprintf(`"%s"`, postgresArrayEscaper.Replace(fmt.Sprintf(`\x%x`, v)))
+		default:
+			if s, err = format(element, v); err == nil {
+				s = fmt.Sprintf(`"%s"`, postgresArrayEscaper.Replace(s))
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		literals[i] = s
+	}
+	return fmt.Sprintf("{%s}", strings.Join(literals, ",")), nil
+}
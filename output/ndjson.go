@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+// NDJSONWriter writes every row as a JSON object on its own line, NULL values
+// are written as null and empty strings as "".
+type NDJSONWriter struct {
+	// Compression defaults to none.
+	Compression Compression
+	// Include and Exclude select the written columns, all columns of the
+	// schema by default.
+	Include []string
+	Exclude []string
+
+	s    *schema.Schema
+	out  *compressedWriter
+	enc  *json.Encoder
+	keys []string
+}
+
+func NewNDJSONWriter(s *schema.Schema, w io.Writer) *NDJSONWriter {
+	n := &NDJSONWriter{s: s}
+	n.out = newCompressedWriter(w, &n.Compression)
+	n.enc = json.NewEncoder(n.out)
+	return n
+}
+
+func (n *NDJSONWriter) WriteRow(row map[string]interface{}) error {
+	if n.keys == nil {
+		keys, err := selectKeys(n.s, n.Include, n.Exclude)
+		if err != nil {
+			return err
+		}
+		n.keys = keys
+	}
+
+	object := make(map[string]interface{}, len(n.keys))
+	for _, k := range n.keys {
+		switch v := row[k].(type) {
+		case time.Time, []byte:
+			s, err := format(n.s.Fields[k], v)
+			if err != nil {
+				return err
+			}
+			object[k] = s
+		case *big.Int:
+			// Integers beyond int64 are quoted, as JSON decoders commonly lose
+			// their precision.
+			if v.IsInt64() {
+				object[k] = v
+			} else {
+				object[k] = v.String()
+			}
+		default:
+			object[k] = v
+		}
+	}
+
+	if err := n.enc.Encode(object); err != nil {
+		return fmt.Errorf("write ndjson row: %s", err)
+	}
+	return nil
+}
+
+func (n *NDJSONWriter) Close() error {
+	return n.out.Close()
+}
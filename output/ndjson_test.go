@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/luncj/mess/schema"
+)
+
+func bigIntSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	f := schema.Field{Type: schema.FieldTypeInt}
+	f.Int.Min = new(big.Int)
+	f.Int.Max = new(big.Int).Lsh(big.NewInt(1), 80)
+	s, err := schema.NewBuilder("t").AddField("id", f).PrimaryKey("id").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestNDJSONWriterBigInt(t *testing.T) {
+	above, _ := new(big.Int).SetString("9223372036854775808", 10)
+	tests := []struct {
+		value *big.Int
+		want  string
+	}{
+		{big.NewInt(math.MaxInt64), `{"id":9223372036854775807}`},
+		{big.NewInt(math.MinInt64), `{"id":-9223372036854775808}`},
+		{above, `{"id":"9223372036854775808"}`},
+		{new(big.Int).Lsh(big.NewInt(1), 80), `{"id":"1208925819614629174706176"}`},
+	}
+
+	s := bigIntSchema(t)
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := NewNDJSONWriter(s, &buf)
+		if err := w.WriteRow(map[string]interface{}{"id": test.value}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSuffix(buf.String(), "\n"); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestNDJSONWriterNullAndEmptyString(t *testing.T) {
+	s := emptyStringSchema(t)
+	rows, err := s.GenerateRows(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := NewNDJSONWriter(s, &buf)
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(rows) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(rows))
+	}
+	for i, row := range rows {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(lines[i]), &object); err != nil {
+			t.Fatal(err)
+		}
+		want := `""`
+		if schema.IsNull(row["s"]) {
+			want = "null"
+		}
+		if got := string(object["s"]); got != want {
+			t.Errorf("row %d: got s = %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+// Writer writes generated rows.
+type Writer interface {
+	WriteRow(row map[string]interface{}) error
+	// Close flushes buffered rows, it does not close the underlying writer.
+	Close() error
+}
+
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02 15:04:05"
+	timeLayout     = "15:04:05"
+)
+
+// format renders a non-nil generated value as text.
+func format(f schema.Field, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case time.Time:
+		switch f.Type {
+		case schema.FieldTypeDate:
+			return v.Format(dateLayout), nil
+		case schema.FieldTypeTime:
+			return v.Format(timeLayout), nil
+		default:
+			if f.Location() != nil {
+				return v.Format(datetimeLayout + "-07:00"), nil
+			}
+			return v.Format(datetimeLayout), nil
+		}
+	case float64:
+		return strconv.FormatFloat(v, 'f', f.Scale(), 64), nil
+	case []byte:
+		return fmt.Sprintf("%x", v), nil
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("marshal %s: %s", f.Type, err)
+		}
+		return string(b), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// selectKeys returns the keys of the schema of which include has or exclude
+// has not when given, in the order of the schema.
+func selectKeys(s *schema.Schema, include, exclude []string) ([]string, error) {
+	filter := make(map[string]bool, len(include)+len(exclude))
+	for _, k := range include {
+		filter[k] = true
+	}
+	for _, k := range exclude {
+		filter[k] = false
+	}
+	for k := range filter {
+		if _, found := s.Fields[k]; !found {
+			return nil, fmt.Errorf("unknown column %q", k)
+		}
+	}
+	if len(filter) == 0 {
+		return s.Keys(), nil
+	}
+
+	var keys []string
+	for _, k := range s.Keys() {
+		if selected, found := filter[k]; selected || !found && len(include) == 0 {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
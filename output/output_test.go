@@ -0,0 +1,36 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/luncj/mess/schema"
+)
+
+func TestFormatFloat(t *testing.T) {
+	float := schema.Field{Type: schema.FieldTypeFloat}
+	percent := schema.Field{Type: schema.FieldTypePercent}
+	percent.Percent.Scale = 1
+	lat := schema.Field{Type: schema.FieldTypeGeo}
+	lat.Geo.Part = schema.GeoPartLat
+
+	tests := []struct {
+		f    schema.Field
+		v    float64
+		want string
+	}{
+		{float, 12345678.5, "12345678.50"},
+		{float, 1e12, "1000000000000.00"},
+		{percent, 12, "12.0"},
+		{lat, -33.8688, "-33.868800"},
+		{schema.Field{Type: schema.FieldTypeJSON}, 1e-7, "0.0000001"},
+	}
+	for _, test := range tests {
+		got, err := format(test.f, test.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got %s for %v, want %s", test.f.Type, got, test.v, test.want)
+		}
+	}
+}
@@ -0,0 +1,522 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+const parquetMagic = "PAR1"
+
+const defaultRowGroupSize = 100_000
+
+// Physical types of parquet.
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Converted types of parquet, the legacy form of logical types.
+const (
+	parquetNone            = -1
+	parquetUTF8            = 0
+	parquetDate            = 6
+	parquetTimeMillis      = 7
+	parquetTimestampMillis = 9
+	parquetJSON            = 19
+)
+
+const (
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// Compression codecs of parquet.
+const (
+	parquetUncompressed = 0
+	parquetGzip         = 2
+)
+
+// ParquetWriter writes rows as a parquet file with PLAIN encoded columns.
+// Rows are buffered and written as a row group every RowGroupSize rows, the
+// footer is written on Close.
+type ParquetWriter struct {
+	// RowGroupSize defaults to 100,000 rows.
+	RowGroupSize int
+	// Compression defaults to none, gzip compresses the pages with the GZIP
+	// codec of parquet so that the file stays readable as parquet.
+	Compression Compression
+	// Include and Exclude select the written columns, all columns of the
+	// schema by default.
+	Include []string
+	Exclude []string
+
+	s         *schema.Schema
+	w         io.Writer
+	columns   []*parquetColumn
+	selected  bool
+	buffered  int
+	offset    int64
+	numRows   int64
+	rowGroups []parquetRowGroup
+}
+
+type parquetColumn struct {
+	name      string
+	field     schema.Field
+	typ       int32
+	converted int32
+	optional  bool
+	values    []interface{}
+}
+
+type parquetRowGroup struct {
+	numRows int64
+	size    int64
+	chunks  []parquetChunk
+}
+
+type parquetChunk struct {
+	offset           int64
+	size             int64
+	uncompressedSize int64
+}
+
+func NewParquetWriter(s *schema.Schema, w io.Writer) *ParquetWriter {
+	columns := make([]*parquetColumn, len(s.Keys()))
+	for i, k := range s.Keys() {
+		f := s.Fields[k]
+		typ, converted := parquetType(f)
+		columns[i] = &parquetColumn{
+			name:      k,
+			field:     f,
+			typ:       typ,
+			converted: converted,
+			optional:  f.IsNullable(),
+		}
+	}
+
+	return &ParquetWriter{
+		s:       s,
+		w:       w,
+		columns: columns,
+	}
+}
+
+// parquetType maps a field to the physical and converted types of its column.
+func parquetType(f schema.Field) (int32, int32) {
+	switch f.Type {
+	case schema.FieldTypeBool:
+		return parquetBoolean, parquetNone
+	case schema.FieldTypeInt:
+		if min, max := f.IntRange(); fitsInt64(min) && fitsInt64(max) {
+			return parquetInt64, parquetNone
+		}
+	case schema.FieldTypeSequence, schema.FieldTypeSnowflake, schema.FieldTypeAge:
+		return parquetInt64, parquetNone
+	case schema.FieldTypeDuration:
+		if f.Duration.Unit != "" {
+			return parquetInt64, parquetNone
+		}
+	case schema.FieldTypeFloat, schema.FieldTypePercent:
+		return parquetDouble, parquetNone
+	case schema.FieldTypeGeo:
+		if f.Geo.Part != schema.GeoPartPoint {
+			return parquetDouble, parquetNone
+		}
+	case schema.FieldTypeDate, schema.FieldTypeDateTime, schema.FieldTypeTime:
+		if format := f.TimeFormat(); format == schema.TimeFormatEpoch || format == schema.TimeFormatEpochMillis {
+			return parquetInt64, parquetNone
+		} else if format != "" {
+			break
+		}
+		switch f.Type {
+		case schema.FieldTypeDate:
+			return parquetInt32, parquetDate
+		case schema.FieldTypeTime:
+			return parquetInt32, parquetTimeMillis
+		default:
+			return parquetInt64, parquetTimestampMillis
+		}
+	case schema.FieldTypeBinary:
+		if f.Binary.Encoding == "" || f.Binary.Encoding == schema.BinaryEncodingRaw {
+			return parquetByteArray, parquetNone
+		}
+	case schema.FieldTypeJSON, schema.FieldTypeArray, schema.FieldTypeObject:
+		return parquetByteArray, parquetJSON
+	case schema.FieldTypeSet:
+		if f.Set.Format == schema.SetFormatJSON {
+			return parquetByteArray, parquetJSON
+		}
+	}
+	return parquetByteArray, parquetUTF8
+}
+
+func fitsInt64(v *big.Int) bool {
+	return v == nil || v.IsInt64()
+}
+
+// selectColumns keeps the columns selected by Include and Exclude.
+func (p *ParquetWriter) selectColumns() error {
+	if p.selected {
+		return nil
+	}
+	keys, err := selectKeys(p.s, p.Include, p.Exclude)
+	if err != nil {
+		return err
+	}
+	columns := make([]*parquetColumn, 0, len(keys))
+	for _, c := range p.columns {
+		for _, k := range keys {
+			if c.name == k {
+				columns = append(columns, c)
+				break
+			}
+		}
+	}
+	p.columns = columns
+	p.selected = true
+	return nil
+}
+
+func (p *ParquetWriter) WriteRow(row map[string]interface{}) error {
+	if err := p.selectColumns(); err != nil {
+		return err
+	}
+	for _, c := range p.columns {
+		v, err := c.convert(row[c.name])
+		if err != nil {
+			return fmt.Errorf("convert %q for parquet: %s", c.name, err)
+		}
+		c.values = append(c.values, v)
+	}
+	p.buffered++
+
+	size := p.RowGroupSize
+	if size <= 0 {
+		size = defaultRowGroupSize
+	}
+	if p.buffered >= size {
+		return p.flush()
+	}
+	return nil
+}
+
+// convert converts a generated value to the go type of the physical type of
+// the column.
+func (c *parquetColumn) convert(value interface{}) (interface{}, error) {
+	if schema.IsNull(value) {
+		if !c.optional {
+			return nil, fmt.Errorf("unexpected NULL for required column")
+		}
+		return nil, nil
+	}
+
+	switch c.typ {
+	case parquetBoolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case parquetInt32:
+		if v, ok := value.(time.Time); ok {
+			if c.converted == parquetDate {
+				days := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86_400
+				return int32(days), nil
+			}
+			h, m, s := v.Clock()
+			return int32(((h*60+m)*60 + s) * 1_000), nil
+		}
+	case parquetInt64:
+		switch v := value.(type) {
+		case *big.Int:
+			return v.Int64(), nil
+		case int64:
+			return v, nil
+		case time.Time:
+			return v.UnixNano() / int64(time.Millisecond), nil
+		}
+	case parquetDouble:
+		if v, ok := value.(float64); ok {
+			return v, nil
+		}
+	case parquetByteArray:
+		if v, ok := value.([]byte); ok {
+			return v, nil
+		}
+		s, err := format(c.field, value)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("unexpected value %v of type %T", value, value)
+}
+
+func (p *ParquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("write parquet: %s", err)
+	}
+	return nil
+}
+
+// codec returns the parquet codec of the compression.
+func (p *ParquetWriter) codec() (int32, error) {
+	switch p.Compression {
+	case "", CompressionNone:
+		return parquetUncompressed, nil
+	case CompressionGzip:
+		return parquetGzip, nil
+	default:
+		_, err := CompressionFromString(p.Compression.String())
+		return 0, err
+	}
+}
+
+// compressPage compresses a page with the codec.
+func compressPage(codec int32, page []byte) ([]byte, error) {
+	if codec == parquetUncompressed {
+		return page, nil
+	}
+	b := bytes.Buffer{}
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write(page); err != nil {
+		return nil, fmt.Errorf("compress parquet page: %s", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress parquet page: %s", err)
+	}
+	return b.Bytes(), nil
+}
+
+func (p *ParquetWriter) flush() error {
+	codec, err := p.codec()
+	if err != nil {
+		return err
+	}
+	if p.offset == 0 {
+		if err := p.write([]byte(parquetMagic)); err != nil {
+			return err
+		}
+	}
+	if p.buffered == 0 {
+		return nil
+	}
+
+	rg := parquetRowGroup{numRows: int64(p.buffered)}
+	for _, c := range p.columns {
+		page := c.encode()
+		data, err := compressPage(codec, page)
+		if err != nil {
+			return err
+		}
+
+		t := &thriftWriter{}
+		t.structElement(func() {
+			t.i32(1, 0) // DATA_PAGE
+			t.i32(2, int32(len(page)))
+			t.i32(3, int32(len(data)))
+			t.structField(5, func() {
+				t.i32(1, int32(len(c.values)))
+				t.i32(2, parquetPlain)
+				t.i32(3, parquetRLE)
+				t.i32(4, parquetRLE)
+			})
+		})
+
+		chunk := parquetChunk{
+			offset:           p.offset,
+			size:             int64(len(t.buf) + len(data)),
+			uncompressedSize: int64(len(t.buf) + len(page)),
+		}
+		if err := p.write(t.buf); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		rg.chunks = append(rg.chunks, chunk)
+		rg.size += chunk.uncompressedSize
+
+		c.values = c.values[:0]
+	}
+
+	p.rowGroups = append(p.rowGroups, rg)
+	p.numRows += rg.numRows
+	p.buffered = 0
+
+	return nil
+}
+
+// encode encodes the buffered values as a data page, the definition levels of
+// optional columns are RLE encoded before the PLAIN encoded non-null values.
+func (c *parquetColumn) encode() []byte {
+	var page []byte
+
+	if c.optional {
+		var levels []byte
+		for i := 0; i < len(c.values); {
+			j := i
+			for j < len(c.values) && (c.values[j] == nil) == (c.values[i] == nil) {
+				j++
+			}
+			levels = appendUvarint(levels, uint64(j-i)<<1)
+			if c.values[i] == nil {
+				levels = append(levels, 0)
+			} else {
+				levels = append(levels, 1)
+			}
+			i = j
+		}
+		page = make([]byte, 4, 4+len(levels))
+		binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+	}
+
+	var bits, numBits int
+	for _, value := range c.values {
+		switch v := value.(type) {
+		case bool:
+			if v {
+				bits |= 1 << numBits
+			}
+			if numBits++; numBits == 8 {
+				page = append(page, byte(bits))
+				bits, numBits = 0, 0
+			}
+		case int32:
+			page = append(page, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(page[len(page)-4:], uint32(v))
+		case int64:
+			page = append(page, 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint64(page[len(page)-8:], uint64(v))
+		case float64:
+			page = append(page, 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint64(page[len(page)-8:], math.Float64bits(v))
+		case []byte:
+			page = append(page, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(page[len(page)-4:], uint32(len(v)))
+			page = append(page, v...)
+		}
+	}
+	if numBits > 0 {
+		page = append(page, byte(bits))
+	}
+
+	return page
+}
+
+func (p *ParquetWriter) Close() error {
+	if err := p.selectColumns(); err != nil {
+		return err
+	}
+	if err := p.flush(); err != nil {
+		return err
+	}
+
+	t := &thriftWriter{}
+	t.structElement(func() {
+		t.i32(1, 1)
+		t.list(2, thriftStruct, len(p.columns)+1)
+		t.structElement(func() {
+			t.string(4, "schema")
+			t.i32(5, int32(len(p.columns)))
+		})
+		for _, c := range p.columns {
+			c.writeSchema(t)
+		}
+		t.i64(3, p.numRows)
+		t.list(4, thriftStruct, len(p.rowGroups))
+		for _, rg := range p.rowGroups {
+			p.writeRowGroup(t, rg)
+		}
+		t.string(6, "mess")
+	})
+
+	footer := make([]byte, 4)
+	binary.LittleEndian.PutUint32(footer, uint32(len(t.buf)))
+
+	if err := p.write(t.buf); err != nil {
+		return err
+	}
+	if err := p.write(footer); err != nil {
+		return err
+	}
+	return p.write([]byte(parquetMagic))
+}
+
+func (c *parquetColumn) writeSchema(t *thriftWriter) {
+	t.structElement(func() {
+		t.i32(1, c.typ)
+		if c.optional {
+			t.i32(3, 1) // OPTIONAL
+		} else {
+			t.i32(3, 0) // REQUIRED
+		}
+		t.string(4, c.name)
+		if c.converted == parquetNone {
+			return
+		}
+		t.i32(6, c.converted)
+		t.structField(10, func() {
+			switch c.converted {
+			case parquetUTF8:
+				t.structField(1, func() {})
+			case parquetDate:
+				t.structField(6, func() {})
+			case parquetTimeMillis:
+				t.structField(7, func() {
+					t.bool(1, false)
+					t.structField(2, func() { t.structField(1, func() {}) })
+				})
+			case parquetTimestampMillis:
+				t.structField(8, func() {
+					t.bool(1, true)
+					t.structField(2, func() { t.structField(1, func() {}) })
+				})
+			case parquetJSON:
+				t.structField(12, func() {})
+			}
+		})
+	})
+}
+
+func (p *ParquetWriter) writeRowGroup(t *thriftWriter, rg parquetRowGroup) {
+	// Close fails on an invalid compression before writing the footer.
+	codec, _ := p.codec()
+	t.structElement(func() {
+		t.list(1, thriftStruct, len(rg.chunks))
+		for i, chunk := range rg.chunks {
+			c := p.columns[i]
+			t.structElement(func() {
+				t.i64(2, chunk.offset)
+				t.structField(3, func() {
+					t.i32(1, c.typ)
+					t.list(2, thriftI32, 2)
+					t.varint(parquetPlain)
+					t.varint(parquetRLE)
+					t.list(3, thriftBinary, 1)
+					t.bytes([]byte(c.name))
+					t.i32(4, codec)
+					t.i64(5, rg.numRows)
+					t.i64(6, chunk.uncompressedSize)
+					t.i64(7, chunk.size)
+					t.i64(9, chunk.offset)
+				})
+			})
+		}
+		t.i64(2, rg.size)
+		t.i64(3, rg.numRows)
+	})
+}
@@ -0,0 +1,305 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+// thriftReader decodes structs of the thrift compact protocol into maps of
+
+// their field ids, which is all the metadata reading of the tests needs.
+
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		panic("invalid varint")
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftTrue:
+		return true
+	case thriftFalse:
+		return false
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		b := r.buf[r.pos : r.pos+n]
+		r.pos += n
+		return b
+	case thriftList:
+		header := r.buf[r.pos]
+		r.pos++
+		n := int(header >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		elements := make([]interface{}, n)
+		for i := range elements {
+			elements[i] = r.value(header & 0x0f)
+		}
+		return elements
+	case thriftStruct:
+		return r.structValue()
+	default:
+		panic(fmt.Sprintf("unexpected thrift type %d", typ))
+	}
+}
+
+func (r *thriftReader) structValue() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		header := r.buf[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0f)
+		last = id
+	}
+}
+
+// readParquet reads the rows of a parquet file written by ParquetWriter,
+
+// decompressing GZIP pages and decoding the RLE definition levels and PLAIN
+
+// values. Byte arrays are read as strings.
+func readParquet(t *testing.T, b []byte) []map[string]interface{} {
+	t.Helper()
+	if string(b[:4]) != parquetMagic || string(b[len(b)-4:]) != parquetMagic {
+		t.Fatal("missing parquet magic")
+	}
+	size := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	meta := (&thriftReader{buf: b[len(b)-8-size : len(b)-8]}).structValue()
+
+	var rows []map[string]interface{}
+	elements := meta[2].([]interface{})[1:]
+	for _, rg := range meta[4].([]interface{}) {
+		numRows := int(rg.(map[int16]interface{})[3].(int64))
+		offset := len(rows)
+		for i := 0; i < numRows; i++ {
+			rows = append(rows, map[string]interface{}{})
+		}
+
+		for i, chunk := range rg.(map[int16]interface{})[1].([]interface{}) {
+			element := elements[i].(map[int16]interface{})
+			name := string(element[4].([]byte))
+			typ := element[1].(int64)
+			optional := element[3].(int64) == 1
+
+			chunkMeta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			r := &thriftReader{buf: b, pos: int(chunkMeta[9].(int64))}
+			header := r.structValue()
+			page := b[r.pos : r.pos+int(header[3].(int64))]
+			if codec := chunkMeta[4].(int64); codec == parquetGzip {
+				gz, err := gzip.NewReader(bytes.NewReader(page))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if page, err = ioutil.ReadAll(gz); err != nil {
+					t.Fatal(err)
+				}
+			} else if codec != parquetUncompressed {
+				t.Fatalf("unexpected codec %d", codec)
+			}
+			if len(page) != int(header[2].(int64)) {
+				t.Fatalf("got page of %d bytes, want uncompressed size %d", len(page), header[2])
+			}
+
+			defined := make([]bool, numRows)
+			for j := range defined {
+				defined[j] = true
+			}
+			if optional {
+				levels := &thriftReader{buf: page[4 : 4+binary.LittleEndian.Uint32(page)]}
+				for j := 0; levels.pos < len(levels.buf); {
+					run := levels.uvarint()
+					if run&1 != 0 {
+						t.Fatal("unexpected bit-packed definition levels")
+					}
+					level := levels.buf[levels.pos]
+					levels.pos++
+					for n := run >> 1; n > 0; n-- {
+						defined[j] = level == 1
+						j++
+					}
+				}
+				page = page[4+len(levels.buf):]
+			}
+
+			var bit int
+			for j := 0; j < numRows; j++ {
+				row := rows[offset+j]
+				if !defined[j] {
+					row[name] = nil
+					continue
+				}
+				switch typ {
+				case parquetBoolean:
+					row[name] = page[bit/8]>>(bit%8)&1 == 1
+					bit++
+				case parquetInt32:
+					row[name] = int32(binary.LittleEndian.Uint32(page))
+					page = page[4:]
+				case parquetInt64:
+					row[name] = int64(binary.LittleEndian.Uint64(page))
+					page = page[8:]
+				case parquetDouble:
+					row[name] = math.Float64frombits(binary.LittleEndian.Uint64(page))
+					page = page[8:]
+				case parquetByteArray:
+					n := binary.LittleEndian.Uint32(page)
+					row[name] = string(page[4 : 4+n])
+					page = page[4+n:]
+				}
+			}
+		}
+	}
+	return rows
+}
+
+func TestParquetWriterRoundTrip(t *testing.T) {
+	s := schemaFromJSON(t, `{
+		"table": "t",
+		"primary_keys": ["id"],
+		"fields": {
+			"id": {"type": "int", "int": {"min": 1, "max": 1000000}},
+			"s": {"type": "string", "string": {"type": "ascii", "ascii": {"min_length": 1, "max_length": 10}}, "nullable_rate": 30},
+			"b": {"type": "bool", "nullable_rate": 30},
+			"created_at": {"type": "datetime", "nullable_rate": 30},
+			"f": {"type": "float", "float": {"min": 0, "max": 1}}
+		}
+	}`)
+
+	rows := make([]map[string]interface{}, 50)
+	want := make([]map[string]interface{}, len(rows))
+	nulls := 0
+	for i := range rows {
+		row, err := s.GenerateRow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows[i] = row
+		want[i] = map[string]interface{}{}
+		for k, v := range rows[i] {
+			switch v := v.(type) {
+			case *big.Int:
+				want[i][k] = v.Int64()
+			case time.Time:
+				want[i][k] = v.UnixNano() / int64(time.Millisecond)
+			case schema.Null:
+				want[i][k] = nil
+				nulls++
+			default:
+				want[i][k] = v
+			}
+		}
+	}
+	if nulls == 0 {
+		t.Fatal("got no NULL values")
+	}
+
+	for _, compression := range []Compression{CompressionNone, CompressionGzip} {
+		var buf bytes.Buffer
+		w := NewParquetWriter(s, &buf)
+		w.Compression = compression
+		w.RowGroupSize = 20
+		for _, row := range rows {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := readParquet(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: read rows differ from the written ones", compression)
+		}
+	}
+}
+
+func TestParquetWriterInvalidCompression(t *testing.T) {
+	s, err := schema.NewBuilder("t").AddInt("id", 1, 10).PrimaryKey("id").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewParquetWriter(s, ioutil.Discard)
+	w.Compression = "zstd"
+	if err := w.Close(); err == nil {
+		t.Fatal("got no error for an invalid compression")
+	}
+}
+
+// thriftReader decodes structs of the thrift compact protocol into maps of
+
+// their field ids, which is all the metadata reading of the tests needs.
+func TestParquetWriterDefault(t *testing.T) {
+	f := schema.Field{Type: schema.FieldTypeFloat, Default: json.RawMessage(`0`), UseDefaultRate: 50}
+	s, err := schema.NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddField("f", f).
+		AddString("s", 1, 10).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f = s.Fields["s"]
+	f.Default, f.UseDefaultRate = json.RawMessage(`null`), 50
+	s.Fields["s"] = f
+
+	rows, err := s.GenerateRows(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := NewParquetWriter(s, &buf)
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, row := range readParquet(t, buf.Bytes()) {
+		if schema.IsNull(rows[i]["s"]) != (row["s"] == nil) {
+			t.Errorf("row %d: got s %v, want %v", i, row["s"], rows[i]["s"])
+		}
+		if row["f"] != rows[i]["f"] {
+			t.Errorf("row %d: got f %v, want %v", i, row["f"], rows[i]["f"])
+		}
+	}
+}
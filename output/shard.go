@@ -0,0 +1,96 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ShardedWriter writes rows across files of ShardSize rows each, or one file
+// when ShardSize is not positive. The files are named after the path with a
+// shard number before its extensions, e.g. data-0001.csv, data-0002.csv for
+// data.csv, and every shard is written by a new Writer from open, so CSV
+// headers are written to every shard. A shard is created by its first row, no
+// file is created when no row is written.
+type ShardedWriter struct {
+	ShardSize int
+
+	path  string
+	open  func(w io.Writer) Writer
+	file  *os.File
+	w     Writer
+	rows  int
+	paths []string
+}
+
+func NewShardedWriter(path string, shardSize int, open func(w io.Writer) Writer) *ShardedWriter {
+	return &ShardedWriter{
+		ShardSize: shardSize,
+		path:      path,
+		open:      open,
+	}
+}
+
+func (s *ShardedWriter) WriteRow(row map[string]interface{}) error {
+	if s.w != nil && s.ShardSize > 0 && s.rows >= s.ShardSize {
+		if err := s.closeShard(); err != nil {
+			return err
+		}
+	}
+	if s.w == nil {
+		if err := s.openShard(); err != nil {
+			return err
+		}
+	}
+
+	s.rows++
+	return s.w.WriteRow(row)
+}
+
+// Close closes the last shard.
+func (s *ShardedWriter) Close() error {
+	if s.w == nil {
+		return nil
+	}
+	return s.closeShard()
+}
+
+// Paths returns the paths of the shards created so far.
+func (s *ShardedWriter) Paths() []string {
+	return s.paths
+}
+
+func (s *ShardedWriter) openShard() error {
+	path := shardPath(s.path, len(s.paths)+1)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create shard: %s", err)
+	}
+
+	s.file = f
+	s.w = s.open(f)
+	s.rows = 0
+	s.paths = append(s.paths, path)
+	return nil
+}
+
+func (s *ShardedWriter) closeShard() error {
+	err := s.w.Close()
+	if cerr := s.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("close shard: %s", cerr)
+	}
+	s.file, s.w = nil, nil
+	return err
+}
+
+// shardPath inserts the shard number n before the extensions of path.
+func shardPath(path string, n int) string {
+	dir, base := filepath.Split(path)
+	name, ext := base, ""
+	if i := strings.Index(base, "."); i > 0 {
+		name, ext = base[:i], base[i:]
+	}
+	return fmt.Sprintf("%s%s-%04d%s", dir, name, n, ext)
+}
@@ -0,0 +1,231 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+type SQLMode string
+
+const (
+	SQLModeInsert       SQLMode = "insert"
+	SQLModeUpsert       SQLMode = "upsert"
+	SQLModeInsertIgnore SQLMode = "insert-ignore"
+)
+
+func (m SQLMode) String() string {
+	return string(m)
+}
+
+func SQLModeFromString(s string) (SQLMode, error) {
+	switch s {
+	case SQLModeInsert.String():
+		return SQLModeInsert, nil
+	case SQLModeUpsert.String():
+		return SQLModeUpsert, nil
+	case SQLModeInsertIgnore.String():
+		return SQLModeInsertIgnore, nil
+	default:
+		return "", fmt.Errorf("invalid sql mode %q, required (%q / %q / %q)", s, SQLModeInsert, SQLModeUpsert, SQLModeInsertIgnore)
+	}
+}
+
+// SQLWriter writes rows as INSERT statements.
+type SQLWriter struct {
+	// Dialect defaults to MySQL.
+	Dialect Dialect
+	// Mode defaults to insert. Upsert updates the rows of which the primary
+	// key exists, insert-ignore skips them.
+	Mode SQLMode
+	// BatchSize is the number of rows grouped into one INSERT statement, it
+	// defaults to 1.
+	BatchSize int
+	// Compression defaults to none.
+	Compression Compression
+	// Include and Exclude select the written columns, all columns of the
+	// schema by default.
+	Include []string
+	Exclude []string
+
+	s      *schema.Schema
+	w      *compressedWriter
+	keys   []string
+	values []string
+}
+
+func NewSQLWriter(s *schema.Schema, w io.Writer) *SQLWriter {
+	q := &SQLWriter{s: s}
+	q.w = newCompressedWriter(w, &q.Compression)
+	return q
+}
+
+func (q *SQLWriter) WriteRow(row map[string]interface{}) error {
+	if q.Dialect == "" {
+		q.Dialect = DialectMySQL
+	} else if _, err := DialectFromString(q.Dialect.String()); err != nil {
+		return err
+	}
+
+	if q.Mode == "" {
+		q.Mode = SQLModeInsert
+	} else if _, err := SQLModeFromString(q.Mode.String()); err != nil {
+		return err
+	}
+
+	if q.keys == nil {
+		keys, err := selectKeys(q.s, q.Include, q.Exclude)
+		if err != nil {
+			return err
+		}
+		if q.Mode != SQLModeInsert {
+			if err := q.checkPrimaryKeys(keys); err != nil {
+				return err
+			}
+		}
+		q.keys = keys
+	}
+
+	values := make([]string, len(q.keys))
+	for i, k := range q.keys {
+		v, err := q.literal(q.s.Fields[k], row[k])
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	q.values = append(q.values, fmt.Sprintf("(%s)", strings.Join(values, ",")))
+
+	if len(q.values) >= q.BatchSize {
+		return q.flush()
+	}
+	return nil
+}
+
+func (q *SQLWriter) Close() error {
+	if err := q.flush(); err != nil {
+		return err
+	}
+	return q.w.Close()
+}
+
+func (q *SQLWriter) flush() error {
+	if len(q.values) == 0 {
+		return nil
+	}
+
+	columns := make([]string, len(q.keys))
+	for i, k := range q.keys {
+		columns[i] = q.Dialect.quoteIdentifier(k)
+	}
+
+	insert := "INSERT INTO"
+	if q.Mode == SQLModeInsertIgnore && q.Dialect == DialectMySQL {
+		insert = "INSERT IGNORE INTO"
+	}
+
+	sql := strings.Builder{}
+	{
+		sql.WriteString(fmt.Sprintf("%s %s (", insert, q.Dialect.quoteIdentifier(q.s.Table)))
+		sql.WriteString(strings.Join(columns, ","))
+		sql.WriteString(") VALUES ")
+		sql.WriteString(strings.Join(q.values, ","))
+		sql.WriteString(q.conflictClause())
+		sql.WriteString(";\n")
+	}
+	q.values = q.values[:0]
+
+	if _, err := io.WriteString(q.w, sql.String()); err != nil {
+		return fmt.Errorf("write sql: %s", err)
+	}
+	return nil
+}
+
+func (q *SQLWriter) checkPrimaryKeys(keys []string) error {
+	if len(q.s.PrimaryKeys) == 0 {
+		return fmt.Errorf("%s requires a primary key", q.Mode)
+	}
+	selected := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		selected[k] = true
+	}
+	for _, pk := range q.s.PrimaryKeys {
+		if !selected[pk] {
+			return fmt.Errorf("%s requires primary key column %q, which is not selected", q.Mode, pk)
+		}
+	}
+	return nil
+}
+
+// conflictClause returns the clause of the mode on rows of which the primary
+// key exists.
+func (q *SQLWriter) conflictClause() string {
+	if q.Mode == SQLModeInsert || q.Mode == SQLModeInsertIgnore && q.Dialect == DialectMySQL {
+		return ""
+	}
+
+	var updates []string
+	if q.Mode == SQLModeUpsert {
+		for _, k := range q.keys {
+			if q.s.IsPrimaryKey(k) {
+				continue
+			}
+			c := q.Dialect.quoteIdentifier(k)
+			if q.Dialect == DialectMySQL {
+				updates = append(updates, fmt.Sprintf("%s=VALUES(%s)", c, c))
+			} else {
+				updates = append(updates, fmt.Sprintf("%s=EXCLUDED.%s", c, c))
+			}
+		}
+	}
+
+	if q.Dialect == DialectMySQL {
+		if len(updates) == 0 {
+			c := q.Dialect.quoteIdentifier(q.s.PrimaryKeys[0])
+			updates = append(updates, fmt.Sprintf("%s=%s", c, c))
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ",")
+	}
+
+	target := fmt.Sprintf(" ON CONFLICT (%s)", q.Dialect.quoteIdentifiers(q.s.PrimaryKeys))
+	if len(updates) == 0 {
+		return target + " DO NOTHING"
+	}
+	return target + " DO UPDATE SET " + strings.Join(updates, ",")
+}
+
+// literal renders a generated value as a SQL literal.
+func (q *SQLWriter) literal(f schema.Field, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil, schema.Null:
+		return "NULL", nil
+	case bool:
+		return q.Dialect.bool(v), nil
+	case []byte:
+		return q.Dialect.binary(v), nil
+	case []interface{}:
+		if q.Dialect == DialectPostgres && f.Type == schema.FieldTypeArray {
+			s, err := PostgresArray(f, v)
+			if err != nil {
+				return "", err
+			}
+			return q.Dialect.quoteString(s), nil
+		}
+		s, err := format(f, v)
+		if err != nil {
+			return "", err
+		}
+		return q.Dialect.quoteString(s), nil
+	case string, time.Time, map[string]interface{}:
+		s, err := format(f, v)
+		if err != nil {
+			return "", err
+		}
+		return q.Dialect.quoteString(s), nil
+	default:
+		return format(f, v)
+	}
+}
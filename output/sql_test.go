@@ -0,0 +1,169 @@
+package output
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/luncj/mess/dataset"
+	"github.com/luncj/mess/schema"
+)
+
+// schemaFromJSON reads the schema of the definition from a temporary file.
+func schemaFromJSON(t *testing.T, definition string) *schema.Schema {
+	t.Helper()
+	f, err := ioutil.TempFile("", "mess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(definition); err != nil {
+		f.Close()
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := schema.FromFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func arraySchema(t *testing.T) *schema.Schema {
+	t.Helper()
+	return schemaFromJSON(t, `{
+		"table": "t",
+		"primary_keys": ["id"],
+		"fields": {
+			"id": {"type": "int", "int": {"min": 1, "max": 10}},
+			"tags": {"type": "array", "array": {"element": {"type": "string", "string": {"type": "ascii", "ascii": {"max_length": 10}}}, "max_length": 3}}
+		}
+	}`)
+}
+
+func TestPostgresArray(t *testing.T) {
+	f := arraySchema(t).Fields["tags"]
+	tests := []struct {
+		elements []interface{}
+		want     string
+	}{
+		{[]interface{}{}, `{}`},
+		{[]interface{}{"a", "b c", "d,e"}, `{"a","b c","d,e"}`},
+		{[]interface{}{`"quoted"`, `back\slash`, "{}", nil}, `{"\"quoted\"","back\\slash","{}",NULL}`},
+		{[]interface{}{[]interface{}{"a"}, []interface{}{schema.Null{}}}, `{{"a"},{NULL}}`},
+	}
+	for _, test := range tests {
+		got, err := PostgresArray(f, test.elements)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("PostgresArray(%q) = %s, want %s", test.elements, got, test.want)
+		}
+	}
+}
+
+func TestSQLWriterPostgresArray(t *testing.T) {
+	s := arraySchema(t)
+	row := map[string]interface{}{
+		"id":   big.NewInt(1),
+		"tags": []interface{}{"it's", `"x"`},
+	}
+	for _, test := range []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectPostgres, `INSERT INTO "t" ("id","tags") VALUES (1,'{"it''s","\"x\""}');` + "\n"},
+		{DialectMySQL, "INSERT INTO `t` (`id`,`tags`) VALUES (1,'" + `["it\'s","\\"x\\""]` + "');\n"},
+	} {
+		var buf bytes.Buffer
+		w := NewSQLWriter(s, &buf)
+		w.Dialect = test.dialect
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.want {
+			t.Errorf("%s: got %s, want %s", test.dialect, buf.String(), test.want)
+		}
+	}
+
+	ddl, err := DDL(s, DialectPostgres.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ddl, `"tags" VARCHAR(10)[] NOT NULL`) {
+		t.Errorf("got DDL %s, want tags declared as VARCHAR(10)[]", ddl)
+	}
+}
+
+func TestSQLWriterSeeded(t *testing.T) {
+	generate := func() []byte {
+		s, err := schema.NewBuilder("t").
+			AddInt("id", 1, 1_000_000).
+			PrimaryKey("id").
+			AddString("s", 1, 10).
+			Nullable("s", 30).
+			AddEnum("e", "a", "b", "c").
+			Nullable("e", 50).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dataset.Seed(7)
+		var buf bytes.Buffer
+		w := NewSQLWriter(s, &buf)
+		for i := 0; i < 200; i++ {
+			row, err := s.GenerateRow()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := w.WriteRow(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	first, second := generate(), generate()
+	if !bytes.Equal(first, second) {
+		t.Fatal("SQL written with the same seed differs")
+	}
+	if !bytes.Contains(first, []byte("NULL")) {
+		t.Fatal("got no NULL values")
+	}
+}
+
+func TestSQLWriterBigInt(t *testing.T) {
+	above, _ := new(big.Int).SetString("18446744073709551615", 10)
+
+	var buf bytes.Buffer
+	w := NewSQLWriter(bigIntSchema(t), &buf)
+	for _, v := range []*big.Int{big.NewInt(math.MaxInt64), above} {
+		if err := w.WriteRow(map[string]interface{}{"id": v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "INSERT INTO `t` (`id`) VALUES (9223372036854775807);\n" +
+		"INSERT INTO `t` (`id`) VALUES (18446744073709551615);\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Builder builds a schema in code, the first error of its calls is returned by
+// Build.
+type Builder struct {
+	s   Schema
+	err error
+}
+
+func NewBuilder(table string) *Builder {
+	return &Builder{
+		s: Schema{
+			Table:  table,
+			Fields: make(map[string]Field),
+		},
+	}
+}
+
+// AddField adds a field of any type.
+func (b *Builder) AddField(name string, f Field) *Builder {
+	if _, found := b.s.Fields[name]; found && b.err == nil {
+		b.err = fmt.Errorf("duplicated field %q", name)
+	}
+	b.s.Fields[name] = f
+	return b
+}
+
+func (b *Builder) AddInt(name string, min, max int64) *Builder {
+	f := Field{Type: FieldTypeInt}
+	f.Int.Min = big.NewInt(min)
+	f.Int.Max = big.NewInt(max)
+	return b.AddField(name, f)
+}
+
+func (b *Builder) AddFloat(name string, min, max float64) *Builder {
+	f := Field{Type: FieldTypeFloat}
+	f.Float.Min = &min
+	f.Float.Max = &max
+	return b.AddField(name, f)
+}
+
+// AddString adds an ascii string field of length in [minLength, maxLength).
+func (b *Builder) AddString(name string, minLength, maxLength int) *Builder {
+	f := Field{Type: FieldTypeString}
+	f.String.Type = StringTypeAscii
+	f.String.Ascii.MinLength = minLength
+	f.String.Ascii.MaxLength = maxLength
+	return b.AddField(name, f)
+}
+
+func (b *Builder) AddEnum(name string, options ...string) *Builder {
+	f := Field{Type: FieldTypeEnum}
+	f.Enum.Options = options
+	return b.AddField(name, f)
+}
+
+func (b *Builder) AddBool(name string) *Builder {
+	return b.AddField(name, Field{Type: FieldTypeBool})
+}
+
+func (b *Builder) AddUUID(name string) *Builder {
+	return b.AddField(name, Field{Type: FieldTypeUUID})
+}
+
+func (b *Builder) AddULID(name string) *Builder {
+	return b.AddField(name, Field{Type: FieldTypeULID})
+}
+
+func (b *Builder) AddDateTime(name string) *Builder {
+	return b.AddField(name, Field{Type: FieldTypeDateTime})
+}
+
+// Nullable sets the nullable rate of an added field.
+func (b *Builder) Nullable(name string, rate int) *Builder {
+	f, found := b.s.Fields[name]
+	if !found {
+		if b.err == nil {
+			b.err = fmt.Errorf("nullable field %q is not added", name)
+		}
+		return b
+	}
+	f.NullableRate = rate
+	b.s.Fields[name] = f
+	return b
+}
+
+func (b *Builder) PrimaryKey(keys ...string) *Builder {
+	b.s.PrimaryKeys = keys
+	return b
+}
+
+func (b *Builder) UniqueKey(keys ...string) *Builder {
+	b.s.UniqueKeys = append(b.s.UniqueKeys, keys)
+	return b
+}
+
+// Build validates the schema and sets it up for generation, the builder should
+// not be used afterwards.
+func (b *Builder) Build() (*Schema, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	s := &b.s
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,222 @@
+package schema
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/luncj/mess/dataset"
+)
+
+// maxInferredEnumOptions is the most distinct values a column of strings can
+// have to be inferred as an enum.
+const maxInferredEnumOptions = 10
+
+// csvDateTimeLayouts are the layouts a column is tried to be parsed with to be
+// inferred as a datetime.
+var csvDateTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05"}
+
+// FromCSV infers a schema from the header and up to sampleRows rows of a CSV.
+// The first column of unique values without empty cells is the primary key.
+func FromCSV(r io.Reader, sampleRows int) (*Schema, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %s", err)
+	}
+
+	columns := make([][]string, len(header))
+	for n := 0; n < sampleRows; n++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv row: %s", err)
+		}
+		for i, v := range record {
+			columns[i] = append(columns[i], v)
+		}
+	}
+
+	s := Schema{Fields: make(map[string]Field, len(header))}
+	for i, k := range header {
+		if _, found := s.Fields[k]; found {
+			return nil, fmt.Errorf("duplicated csv column %q", k)
+		}
+		f := inferField(columns[i])
+		s.Fields[k] = f
+		if s.PrimaryKeys == nil && f.NullableRate == 0 && distinct(columns[i]) == len(columns[i]) && len(columns[i]) > 0 {
+			s.PrimaryKeys = []string{k}
+		}
+	}
+	if s.PrimaryKeys == nil {
+		return nil, fmt.Errorf("no csv column of unique values to be the primary key")
+	}
+
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// inferField infers a field from the values of a column, the ratio of empty
+// values is the nullable rate.
+func inferField(values []string) Field {
+	var f Field
+
+	var present []string
+	for _, v := range values {
+		if v != "" {
+			present = append(present, v)
+		}
+	}
+	if empty := len(values) - len(present); empty > 0 {
+		f.NullableRate = empty * 100 / len(values)
+		if f.NullableRate == 0 {
+			f.NullableRate = 1
+		}
+	}
+
+	if len(present) == 0 {
+		f.Type = FieldTypeString
+		f.String.Type = StringTypeWord
+		f.String.Word.Num = 1
+		return f
+	}
+
+	if min, max, ok := intBounds(present); ok {
+		f.Type = FieldTypeInt
+		f.Int.Min, f.Int.Max = min, max
+		return f
+	}
+
+	if min, max, ok := floatBounds(present); ok {
+		f.Type = FieldTypeFloat
+		f.Float.Min, f.Float.Max = &min, &max
+		return f
+	}
+
+	if all(present, func(v string) bool { _, err := strconv.ParseBool(v); return err == nil }) {
+		f.Type = FieldTypeBool
+		return f
+	}
+
+	if min, max, ok := timeBounds(present, dateLayout); ok {
+		f.Type = FieldTypeDate
+		f.Date.Min, f.Date.Max = min.Format(dateLayout), max.Format(dateLayout)
+		return f
+	}
+
+	for _, layout := range csvDateTimeLayouts {
+		if min, max, ok := timeBounds(present, layout); ok {
+			f.Type = FieldTypeDateTime
+			f.DateTime.Min, f.DateTime.Max = min.Format(time.RFC3339), max.Format(time.RFC3339)
+			if layout != time.RFC3339 {
+				f.DateTime.Format = layout
+			}
+			return f
+		}
+	}
+
+	if n := distinct(present); n <= maxInferredEnumOptions && n*2 <= len(present) {
+		f.Type = FieldTypeEnum
+		seen := make(map[string]bool, n)
+		for _, v := range present {
+			if !seen[v] {
+				seen[v] = true
+				f.Enum.Options = append(f.Enum.Options, v)
+			}
+		}
+		sort.Strings(f.Enum.Options)
+		return f
+	}
+
+	f.Type = FieldTypeString
+	f.String.Type = StringTypeAscii
+	f.String.Ascii.Charset = dataset.CharsetAlphanumeric
+	f.String.Ascii.MinLength = len(present[0])
+	for _, v := range present {
+		if len(v) < f.String.Ascii.MinLength {
+			f.String.Ascii.MinLength = len(v)
+		}
+		if len(v) >= f.String.Ascii.MaxLength {
+			f.String.Ascii.MaxLength = len(v) + 1
+		}
+	}
+	return f
+}
+
+func all(values []string, fn func(string) bool) bool {
+	for _, v := range values {
+		if !fn(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func distinct(values []string) int {
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		seen[v] = true
+	}
+	return len(seen)
+}
+
+func intBounds(values []string) (*big.Int, *big.Int, bool) {
+	var min, max *big.Int
+	for _, v := range values {
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, nil, false
+		}
+		if min == nil || n.Cmp(min) < 0 {
+			min = n
+		}
+		if max == nil || n.Cmp(max) > 0 {
+			max = n
+		}
+	}
+	return min, max, true
+}
+
+func floatBounds(values []string) (float64, float64, bool) {
+	var min, max float64
+	for i, v := range values {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		if i == 0 || n < min {
+			min = n
+		}
+		if i == 0 || n > max {
+			max = n
+		}
+	}
+	return min, max, true
+}
+
+func timeBounds(values []string, layout string) (time.Time, time.Time, bool) {
+	var min, max time.Time
+	for i, v := range values {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		if i == 0 || t.Before(min) {
+			min = t
+		}
+		if i == 0 || t.After(max) {
+			max = t
+		}
+	}
+	return min, max, true
+}
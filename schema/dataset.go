@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/luncj/mess/dataset"
+)
+
+// Dataset is a set of tables related by foreign keys.
+type Dataset struct {
+	tables map[string]*Schema
+	// parents holds the tables referred by the foreign keys of each table.
+	parents map[string][]string
+	order   []string
+}
+
+// NewDataset collects the schemas of tables into a dataset, their foreign keys
+// should refer to the primary keys of tables in the dataset without cycles.
+func NewDataset(schemas ...*Schema) (*Dataset, error) {
+	d := &Dataset{
+		tables:  make(map[string]*Schema, len(schemas)),
+		parents: make(map[string][]string, len(schemas)),
+	}
+	for _, s := range schemas {
+		if _, found := d.tables[s.Table]; found {
+			return nil, fmt.Errorf("duplicated table %q", s.Table)
+		}
+		d.tables[s.Table] = s
+	}
+
+	for _, s := range schemas {
+		t := s.Table
+		for _, k := range s.keys {
+			f := s.Fields[k]
+			if f.Type != FieldTypeForeignKey {
+				continue
+			}
+			parent, found := d.tables[f.ForeignKey.Table]
+			if !found {
+				return nil, fmt.Errorf("field %q of table %q refers to undefined table %q", k, t, f.ForeignKey.Table)
+			}
+			if !parent.IsPrimaryKey(f.ForeignKey.Field) {
+				return nil, fmt.Errorf("field %q of table %q refers to %s which is not a primary key", k, t, f.reference())
+			}
+			d.parents[t] = append(d.parents[t], parent.Table)
+		}
+	}
+
+	var err error
+	if d.order, err = d.tableOrder(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Tables returns the tables in the order they are generated, referred tables
+// come before the tables referring to them.
+func (d *Dataset) Tables() []string {
+	return d.order
+}
+
+// Generate generates counts[table] rows for every table, keyed by table name.
+// Foreign key fields take their values from the generated primary keys of the
+// tables they refer to.
+func (d *Dataset) Generate(counts map[string]int) (map[string][]map[string]interface{}, error) {
+	return d.GenerateContext(context.Background(), counts)
+}
+
+// GenerateContext generates the rows like Generate, it stops with ctx.Err()
+// when ctx is done.
+func (d *Dataset) GenerateContext(ctx context.Context, counts map[string]int) (map[string][]map[string]interface{}, error) {
+	references := make(map[string][]interface{})
+	generated := make(map[string][]map[string]interface{}, len(d.order))
+	for _, t := range d.order {
+		s := d.tables[t]
+
+		for _, k := range s.keys {
+			f := s.Fields[k]
+			if f.Type == FieldTypeForeignKey && len(references[f.reference()]) == 0 {
+				return nil, fmt.Errorf("field %q of table %q refers to %s which has no rows", k, t, f.reference())
+			}
+		}
+
+		s.references = references
+		rows, err := s.GenerateRowsContext(ctx, counts[t])
+		if err != nil {
+			return nil, fmt.Errorf("generate rows of table %q: %w", t, err)
+		}
+		generated[t] = rows
+
+		for _, pk := range s.PrimaryKeys {
+			ref := fmt.Sprintf("%s.%s", t, pk)
+			for _, row := range rows {
+				if v := row[pk]; !IsNull(v) {
+					references[ref] = append(references[ref], v)
+				}
+			}
+		}
+	}
+
+	return generated, nil
+}
+
+// GenerateDataset generates rows for multiple tables, keyed by table name.
+// Referenced tables are generated before the tables referring to them, so that
+// foreign key fields take their values from the generated primary keys.
+func GenerateDataset(schemas []*Schema, counts map[string]int) (map[string][]map[string]interface{}, error) {
+	d, err := NewDataset(schemas...)
+	if err != nil {
+		return nil, err
+	}
+	return d.Generate(counts)
+}
+
+// reference returns the referenced field of a foreign key as "table.field".
+func (f Field) reference() string {
+	return fmt.Sprintf("%s.%s", f.ForeignKey.Table, f.ForeignKey.Field)
+}
+
+// pickReference picks a value for the foreign key field from the generated
+// primary keys of the referenced table.
+func (s *Schema) pickReference(g *dataset.Generator, f Field) (interface{}, error) {
+	pool := s.references[f.reference()]
+	if len(pool) == 0 {
+		return nil, fmt.Errorf("no generated rows of %s to refer", f.reference())
+	}
+	return pool[g.Intn(len(pool))], nil
+}
+
+// tableOrder sorts the tables topologically by their foreign keys.
+func (d *Dataset) tableOrder() ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	names := make([]string, 0, len(d.tables))
+	for t := range d.tables {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+
+	order := make([]string, 0, len(d.tables))
+	states := make(map[string]int, len(d.tables))
+
+	var visit func(t string, path []string) error
+	visit = func(t string, path []string) error {
+		switch states[t] {
+		case visiting:
+			return fmt.Errorf("cyclic foreign keys between tables: %s", strings.Join(append(path, t), " -> "))
+		case visited:
+			return nil
+		}
+
+		states[t] = visiting
+		for _, parent := range d.parents[t] {
+			if err := visit(parent, append(path, t)); err != nil {
+				return err
+			}
+		}
+		states[t] = visited
+		order = append(order, t)
+
+		return nil
+	}
+
+	for _, t := range names {
+		if err := visit(t, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
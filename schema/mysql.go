@@ -0,0 +1,248 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// inferredNullableRate is the nullable rate of the fields inferred from
+// nullable columns.
+const inferredNullableRate = 10
+
+var mysqlIntBits = map[string]uint{
+	"tinyint":   8,
+	"smallint":  16,
+	"mediumint": 24,
+	"int":       32,
+	"integer":   32,
+	"bigint":    64,
+}
+
+// FromMySQL builds a schema from the columns and the indexes of a table in the
+// current database of db.
+func FromMySQL(db *sql.DB, table string) (*Schema, error) {
+	s := Schema{
+		Table:  table,
+		Fields: make(map[string]Field),
+	}
+
+	rows, err := db.Query(`SELECT column_name, data_type, column_type, is_nullable,
+		character_maximum_length, numeric_precision, numeric_scale
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ?`, table)
+	if err != nil {
+		return nil, fmt.Errorf("query columns of %q: %s", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c mysqlColumn
+		if err := rows.Scan(&c.name, &c.dataType, &c.columnType, &c.nullable, &c.length, &c.precision, &c.scale); err != nil {
+			return nil, fmt.Errorf("scan column of %q: %s", table, err)
+		}
+		f, err := c.field()
+		if err != nil {
+			return nil, fmt.Errorf("infer field %q: %s", c.name, err)
+		}
+		s.Fields[c.name] = f
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query columns of %q: %s", table, err)
+	}
+	if len(s.Fields) == 0 {
+		return nil, fmt.Errorf("table %q not found", table)
+	}
+
+	if err := s.readMySQLIndexes(db); err != nil {
+		return nil, err
+	}
+
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+type mysqlColumn struct {
+	name       string
+	dataType   string
+	columnType string
+	nullable   string
+	length     sql.NullInt64
+	precision  sql.NullInt64
+	scale      sql.NullInt64
+}
+
+// field maps the column to the closest field type.
+func (c mysqlColumn) field() (Field, error) {
+	var f Field
+	if c.nullable == "YES" {
+		f.NullableRate = inferredNullableRate
+	}
+
+	dataType := strings.ToLower(c.dataType)
+	columnType := strings.ToLower(c.columnType)
+	unsigned := strings.Contains(columnType, "unsigned")
+
+	switch dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if strings.HasPrefix(columnType, "tinyint(1)") {
+			f.Type = FieldTypeBool
+			break
+		}
+		f.Type = FieldTypeInt
+		f.Int.Min, f.Int.Max = intRange(mysqlIntBits[dataType], unsigned)
+		f.Int.Unsigned = unsigned
+	case "year":
+		f.Type = FieldTypeInt
+		f.Int.Min, f.Int.Max = big.NewInt(1901), big.NewInt(2155)
+	case "bit":
+		f.Type = FieldTypeInt
+		f.Int.Min, f.Int.Max = intRange(uint(c.precision.Int64), true)
+	case "decimal", "numeric":
+		f.Type = FieldTypeDecimal
+		f.Decimal.Scale = int(c.scale.Int64)
+		max := new(big.Int).Exp(big.NewInt(10), big.NewInt(c.precision.Int64-c.scale.Int64), nil)
+		max.Sub(max, big.NewInt(1))
+		f.Decimal.Max = max
+		if unsigned {
+			f.Decimal.Min = big.NewInt(0)
+		} else {
+			f.Decimal.Min = new(big.Int).Neg(max)
+		}
+	case "float", "double", "real":
+		f.Type = FieldTypeFloat
+		if c.scale.Valid {
+			f.Float.Precision, f.Float.Scale = int(c.precision.Int64), int(c.scale.Int64)
+		}
+		if unsigned {
+			min := 0.0
+			f.Float.Min = &min
+		}
+	case "char", "varchar":
+		f.Type = FieldTypeString
+		f.String.Type = StringTypeAscii
+		f.String.Ascii.MaxLength = int(c.length.Int64)
+		if f.String.Ascii.MaxLength == 0 {
+			f.String.Ascii.MaxLength = 1
+		}
+	case "tinytext":
+		f.Type = FieldTypeString
+		f.String.Type = StringTypeSentence
+		f.String.Sentence.Num = 1
+	case "text", "mediumtext", "longtext":
+		f.Type = FieldTypeString
+		f.String.Type = StringTypeParagraph
+		f.String.Paragraph.Num = 1
+	case "binary", "varbinary":
+		f.Type = FieldTypeBinary
+		f.Binary.MaxLength = int(c.length.Int64)
+		if dataType == "binary" {
+			f.Binary.MinLength = f.Binary.MaxLength
+		}
+	case "tinyblob", "blob", "mediumblob", "longblob":
+		f.Type = FieldTypeBinary
+		f.Binary.MaxLength = 255
+	case "enum":
+		f.Type = FieldTypeEnum
+		f.Enum.Options = parseMySQLOptions(c.columnType)
+	case "set":
+		f.Type = FieldTypeSet
+		f.Set.Options = parseMySQLOptions(c.columnType)
+	case "date":
+		f.Type = FieldTypeDate
+	case "datetime", "timestamp":
+		f.Type = FieldTypeDateTime
+	case "time":
+		f.Type = FieldTypeTime
+	case "json":
+		f.Type = FieldTypeJSON
+	default:
+		return f, fmt.Errorf("unsupported mysql column type %q", c.columnType)
+	}
+
+	return f, nil
+}
+
+// intRange returns the range of an integer of bits.
+func intRange(bits uint, unsigned bool) (*big.Int, *big.Int) {
+	if unsigned {
+		max := new(big.Int).Lsh(big.NewInt(1), bits)
+		return big.NewInt(0), max.Sub(max, big.NewInt(1))
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	min := new(big.Int).Neg(max)
+	return min, max.Sub(max, big.NewInt(1))
+}
+
+// parseMySQLOptions parses the options of a column type like
+// "enum('a','b')".
+func parseMySQLOptions(columnType string) []string {
+	var options []string
+
+	var option strings.Builder
+	quoted := false
+	body := columnType[strings.Index(columnType, "(")+1 : strings.LastIndex(columnType, ")")]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case !quoted:
+			if c == '\'' {
+				quoted = true
+				option.Reset()
+			}
+		case c == '\'' && i+1 < len(body) && body[i+1] == '\'':
+			option.WriteByte(c)
+			i++
+		case c == '\'':
+			quoted = false
+			options = append(options, option.String())
+		default:
+			option.WriteByte(c)
+		}
+	}
+
+	return options
+}
+
+// readMySQLIndexes sets the primary key and the unique keys of the schema from
+// the indexes of its table.
+func (s *Schema) readMySQLIndexes(db *sql.DB) error {
+	rows, err := db.Query(`SELECT index_name, column_name
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = ? AND non_unique = 0
+		ORDER BY index_name, seq_in_index`, s.Table)
+	if err != nil {
+		return fmt.Errorf("query indexes of %q: %s", s.Table, err)
+	}
+	defer rows.Close()
+
+	var names []string
+	indexes := make(map[string][]string)
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return fmt.Errorf("scan index of %q: %s", s.Table, err)
+		}
+		if _, found := indexes[name]; !found {
+			names = append(names, name)
+		}
+		indexes[name] = append(indexes[name], column)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("query indexes of %q: %s", s.Table, err)
+	}
+
+	for _, name := range names {
+		if name == "PRIMARY" {
+			s.PrimaryKeys = indexes[name]
+		} else {
+			s.UniqueKeys = append(s.UniqueKeys, indexes[name])
+		}
+	}
+
+	return nil
+}
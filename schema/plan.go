@@ -0,0 +1,174 @@
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/luncj/mess/dataset"
+)
+
+// Plan describes the rows a schema would generate without generating them.
+type Plan struct {
+	Count   int
+	Columns []string
+	// KeySpaces estimates the distinct values of the primary key and every
+	// unique key.
+	KeySpaces []KeySpace
+	// Warnings describes the problems the generation would run into, e.g. a
+	// key space smaller than Count.
+	Warnings []string
+}
+
+// KeySpace is the number of distinct values of a primary key or unique key,
+// Size is nil when the values are practically unbounded.
+type KeySpace struct {
+	Keys []string
+	Size *big.Int
+}
+
+// Plan validates the schema and estimates whether count rows with unique keys
+// can be generated, without generating any value.
+func (s *Schema) Plan(count int) (*Plan, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("count should not be negative, got %d", count)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Plan{
+		Count:   count,
+		Columns: KeysFromFields(s.Fields),
+	}
+	for _, keys := range s.uniqueKeys() {
+		size := s.keySpace(keys)
+		p.KeySpaces = append(p.KeySpaces, KeySpace{Keys: keys, Size: size})
+		if size == nil {
+			continue
+		}
+
+		n := big.NewInt(int64(count))
+		switch {
+		case size.Cmp(n) < 0:
+			p.Warnings = append(p.Warnings, fmt.Sprintf("key (%s) has %s distinct values, fewer than %d rows",
+				strings.Join(keys, ", "), size, count))
+		case new(big.Int).Lsh(n, 1).Cmp(size) > 0:
+			p.Warnings = append(p.Warnings, fmt.Sprintf("key (%s) has %s distinct values, %d rows of them take many retries to generate",
+				strings.Join(keys, ", "), size, count))
+		}
+	}
+	return p, nil
+}
+
+// keySpace returns the product of the cardinalities of the fields, or nil when
+// any of them is unbounded.
+func (s *Schema) keySpace(keys []string) *big.Int {
+	size := big.NewInt(1)
+	for _, k := range keys {
+		n := s.Fields[k].cardinality()
+		if n == nil {
+			return nil
+		}
+		size.Mul(size, n)
+	}
+	return size
+}
+
+// cardinality estimates the number of distinct values of the field, it returns
+// nil when they are practically unbounded. NULL values never conflict, so
+// nullable fields are unbounded.
+func (f Field) cardinality() *big.Int {
+	if f.IsNullable() {
+		return nil
+	}
+
+	var n *big.Int
+	switch f.Type {
+	case FieldTypeConst:
+		n = big.NewInt(1)
+	case FieldTypeBool:
+		n = big.NewInt(2)
+	case FieldTypeGender:
+		n = big.NewInt(2)
+		if f.Gender.OtherRate > 0 {
+			n = big.NewInt(3)
+		}
+	case FieldTypeEnum:
+		options := make(map[string]bool, len(f.Enum.Options))
+		for _, o := range f.Enum.Options {
+			options[o] = true
+		}
+		n = big.NewInt(int64(len(options)))
+	case FieldTypeSet:
+		// Options are picked in the order of declaration, so the sets are the
+		// subsets of [min, max] options.
+		n = new(big.Int)
+		for k := f.Set.Min; k <= f.setMax(); k++ {
+			n.Add(n, new(big.Int).Binomial(int64(len(f.Set.Options)), int64(k)))
+		}
+	case FieldTypeCountry:
+		n = big.NewInt(int64(dataset.CountryCount()))
+	case FieldTypeCurrencyCode:
+		codes := make(map[string]bool, len(f.CurrencyCode.Only))
+		for _, c := range f.CurrencyCode.Only {
+			codes[c] = true
+		}
+		n = big.NewInt(int64(len(codes)))
+		if len(codes) == 0 {
+			n = big.NewInt(int64(dataset.CurrencyCodeCount()))
+		}
+	case FieldTypeInt:
+		min, max := f.IntRange()
+		if min == nil || max == nil {
+			return nil
+		}
+		if f.Int.Step > 0 {
+			// The values are the multiples of step within [min, max].
+			min = dataset.Multiple(min, min, f.Int.Step)
+		}
+		n = new(big.Int).Sub(max, min)
+		if f.Int.Step > 0 {
+			n.Div(n, big.NewInt(f.Int.Step))
+		}
+		n.Add(n, big.NewInt(1))
+	case FieldTypeAge:
+		n = big.NewInt(int64(f.AgeMax() - f.Age.Min + 1))
+	case FieldTypeHex:
+		n = new(big.Int).Lsh(big.NewInt(1), uint(4*f.Hex.Length))
+	case FieldTypeDate, FieldTypeDateTime, FieldTypeTime:
+		// Monotonic datetimes never repeat.
+		if f.Type == FieldTypeDateTime && f.DateTime.Monotonic {
+			return nil
+		}
+		unit := time.Second
+		if f.Type == FieldTypeDate {
+			unit = 24 * time.Hour
+		}
+		min, max, err := f.dateTimeRange()
+		if err != nil {
+			return nil
+		}
+		if min.IsZero() && max.IsZero() {
+			if f.Type == FieldTypeTime {
+				return f.withDefault(big.NewInt(int64(24 * time.Hour / unit)))
+			}
+			min, max = dataset.DateTimeBounds()
+		}
+		n = big.NewInt(int64(max.Sub(min)/unit) + 1)
+	default:
+		return nil
+	}
+
+	return f.withDefault(n)
+}
+
+// withDefault adds the default value to the cardinality n of the field when it
+// takes it.
+func (f Field) withDefault(n *big.Int) *big.Int {
+	if f.UseDefaultRate > 0 {
+		n.Add(n, big.NewInt(1))
+	}
+	return n
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/luncj/mess/dataset"
+)
+
+func TestPlanFiniteKeySpaces(t *testing.T) {
+	country := Field{Type: FieldTypeCountry}
+	currency := Field{Type: FieldTypeCurrencyCode}
+	currency.CurrencyCode.Only = []string{"EUR", "USD", "JPY"}
+	set := Field{Type: FieldTypeSet}
+	set.Set.Options = []string{"a", "b", "c", "d"}
+	set.Set.Min, set.Set.Max = 1, 2
+
+	s, err := NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddField("country", country).
+		UniqueKey("country").
+		AddField("currency", currency).
+		UniqueKey("currency").
+		AddField("set", set).
+		UniqueKey("set").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := s.Plan(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := map[string]*big.Int{}
+	for _, ks := range p.KeySpaces {
+		sizes[strings.Join(ks.Keys, ",")] = ks.Size
+	}
+	for k, want := range map[string]int64{
+		"country":  int64(dataset.CountryCount()),
+		"currency": 3,
+		// C(4, 1) + C(4, 2)
+		"set": 10,
+	} {
+		if sizes[k] == nil || sizes[k].Int64() != want {
+			t.Errorf("key space of %s = %v, want %d", k, sizes[k], want)
+		}
+	}
+	if len(p.Warnings) != 3 {
+		t.Errorf("got warnings %q, want one per unique key", p.Warnings)
+	}
+}
+
+func TestPlanDrawsNothing(t *testing.T) {
+	regex := Field{Type: FieldTypeRegex}
+	regex.Regex.Pattern = `[A-Z]{3}-[0-9]{4}`
+	s, err := NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddField("code", regex).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataset.Seed(7)
+	want := dataset.Int63()
+
+	dataset.Seed(7)
+	if _, err := s.Plan(10); err != nil {
+		t.Fatal(err)
+	}
+	if got := dataset.Int63(); got != want {
+		t.Fatalf("Plan drew from the seeded source, got %d after it, want %d", got, want)
+	}
+}
+
+func TestPlanStepAndDateTimeKeySpaces(t *testing.T) {
+	step := Field{Type: FieldTypeInt}
+	step.Int.Min, step.Int.Max, step.Int.Step = big.NewInt(1), big.NewInt(10), 3
+	at := Field{Type: FieldTypeDateTime}
+	at.DateTime.Min, at.DateTime.Max = "2020-01-01T00:00:00Z", "2020-01-01T00:01:00Z"
+	series := at
+	series.DateTime.Monotonic, series.DateTime.Interval = true, "1s"
+
+	s, err := NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddField("step", step).
+		UniqueKey("step").
+		AddField("at", at).
+		UniqueKey("at").
+		AddField("series", series).
+		UniqueKey("series").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := s.Plan(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := map[string]*big.Int{}
+	for _, ks := range p.KeySpaces {
+		sizes[strings.Join(ks.Keys, ",")] = ks.Size
+	}
+	// 3, 6 and 9, and the 61 seconds of the minute.
+	for k, want := range map[string]int64{"step": 3, "at": 61} {
+		if sizes[k] == nil || sizes[k].Int64() != want {
+			t.Errorf("key space of %s = %v, want %d", k, sizes[k], want)
+		}
+	}
+	if sizes["series"] != nil {
+		t.Errorf("key space of series = %v, want unbounded", sizes["series"])
+	}
+	if len(p.Warnings) != 2 {
+		t.Errorf("got warnings %q, want step and at to warn", p.Warnings)
+	}
+}
+
+func TestFieldCardinality(t *testing.T) {
+	age := Field{Type: FieldTypeAge}
+	age.Age.Min = 18
+	if n := age.cardinality(); n == nil || n.Int64() != 83 {
+		t.Errorf("cardinality of ages from 18 = %v, want 83", n)
+	}
+
+	null := Field{Type: FieldTypeCountry, Default: json.RawMessage(`null`), UseDefaultRate: 10}
+	if n := null.cardinality(); n != nil {
+		t.Errorf("cardinality of a field of NULL default = %v, want unbounded", n)
+	}
+}
@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/luncj/mess/dataset"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[FieldType]func(*dataset.Generator, Field) (interface{}, error))
+)
+
+// RegisterType registers the generator of a custom field type, fields of the
+// type are generated by calling gen with the field. RegisterType panics if gen
+// is nil or the type is already a built-in or registered type.
+//
+// gen is not given the generator of the field, so that its values are not
+// reproduced by seeding unless gen seeds its own source. Use RegisterTypeWith
+// to draw from the generator of the field.
+func RegisterType(name FieldType, gen func(Field) (interface{}, error)) {
+	if gen == nil {
+		panic("schema: register nil generator of field type " + string(name))
+	}
+	RegisterTypeWith(name, func(_ *dataset.Generator, f Field) (interface{}, error) {
+		return gen(f)
+	})
+}
+
+// RegisterTypeWith registers the generator of a custom field type like
+// RegisterType, fields of the type are generated by calling gen with the
+// generator of the field, which is seeded like those of the built-in types.
+func RegisterTypeWith(name FieldType, gen func(*dataset.Generator, Field) (interface{}, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if gen == nil {
+		panic("schema: register nil generator of field type " + string(name))
+	}
+	if name.isBuiltIn() {
+		panic(fmt.Sprintf("schema: register built-in field type %s", name))
+	}
+	if _, found := registry[name]; found {
+		panic(fmt.Sprintf("schema: register field type %s twice", name))
+	}
+	registry[name] = gen
+}
+
+func (t FieldType) isBuiltIn() bool {
+	for _, builtIn := range fieldTypes {
+		if t == builtIn {
+			return true
+		}
+	}
+	return false
+}
+
+func registeredType(name FieldType) (func(*dataset.Generator, Field) (interface{}, error), bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	gen, found := registry[name]
+	return gen, found
+}
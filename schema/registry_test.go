@@ -0,0 +1,108 @@
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/luncj/mess/dataset"
+)
+
+// schemaFile writes the schema definition to a file of a new directory, which
+// is removed by the returned function.
+func schemaFile(t *testing.T, definition string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "mess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "schema.json")
+	if err := ioutil.WriteFile(path, []byte(definition), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestRegisterType(t *testing.T) {
+	RegisterType("test_sku", func(f Field) (interface{}, error) {
+		return "SKU-0001", nil
+	})
+
+	path, remove := schemaFile(t, `{
+		"table": "products",
+		"primary_keys": ["id"],
+		"fields": {
+			"id": {"type": "sequence"},
+			"sku": {"type": "test_sku"}
+		}
+	}`)
+	defer remove()
+
+	s, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := s.GenerateRow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["sku"] != "SKU-0001" {
+		t.Errorf("got sku %#v, want %q", row["sku"], "SKU-0001")
+	}
+}
+
+func TestUnregisteredType(t *testing.T) {
+	path, remove := schemaFile(t, `{
+		"table": "products",
+		"primary_keys": ["id"],
+		"fields": {
+			"id": {"type": "sequence"},
+			"sku": {"type": "test_unregistered"}
+		}
+	}`)
+	defer remove()
+
+	if _, err := FromFile(path); err == nil || !strings.Contains(err.Error(), "test_unregistered") {
+		t.Errorf("got error %v, want an error of the unregistered type", err)
+	}
+
+	if _, err := (Field{Type: "test_unregistered"}).Generate(); err == nil {
+		t.Error("got no error generating an unregistered type")
+	}
+}
+
+func TestRegisterTypeWith(t *testing.T) {
+	RegisterTypeWith("test_code", func(g *dataset.Generator, f Field) (interface{}, error) {
+		return g.Ascii(8, 9, dataset.CharsetAlphanumeric), nil
+	})
+
+	path, remove := schemaFile(t, `{
+		"table": "products",
+		"primary_keys": ["id"],
+		"fields": {
+			"id": {"type": "sequence"},
+			"code": {"type": "test_code"}
+		}
+	}`)
+	defer remove()
+
+	generate := func() []map[string]interface{} {
+		s, err := FromFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataset.Seed(7)
+		rows, err := s.GenerateRows(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+	if first, second := generate(), generate(); !reflect.DeepEqual(first, second) {
+		t.Errorf("rows generated with the same seed differ: %v and %v", first, second)
+	}
+}
@@ -0,0 +1,431 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/luncj/mess/dataset"
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// GenerateRow generates a value for every field, fields referring to other
+// fields are generated after the ones they refer to. Rows are regenerated until
+// their primary key and unique keys are unique among the rows generated by the
+// schema.
+func (s *Schema) GenerateRow() (map[string]interface{}, error) {
+	return s.GenerateRowWith(dataset.Default())
+}
+
+// GenerateRowWith generates a row from the generator g.
+func (s *Schema) GenerateRowWith(g *dataset.Generator) (map[string]interface{}, error) {
+	attempts := s.maxRetries() + 1
+	var keys []string
+	for i := 0; i < attempts; i++ {
+		row, err := s.generateRow(g)
+		if err != nil {
+			return nil, err
+		}
+		if keys = s.conflict(row); keys == nil {
+			return row, nil
+		}
+	}
+	return nil, ErrKeySpaceExhausted{Field: strings.Join(keys, ", "), Attempts: attempts}
+}
+
+// GenerateRows generates n rows.
+func (s *Schema) GenerateRows(n int) ([]map[string]interface{}, error) {
+	return s.GenerateRowsContext(context.Background(), n)
+}
+
+// GenerateRowsContext generates n rows, it stops with ctx.Err() when ctx is
+// done.
+func (s *Schema) GenerateRowsContext(ctx context.Context, n int) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		row, err := s.GenerateRow()
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = row
+		s.progress(i+1, n)
+	}
+	return rows, nil
+}
+
+const defaultProgressInterval = 1000
+
+// progress reports generated rows of total to the Progress callback.
+func (s *Schema) progress(generated, total int) {
+	if s.Progress == nil {
+		return
+	}
+	interval := s.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	if generated%interval == 0 || generated == total {
+		s.Progress(generated, total)
+	}
+}
+
+func (s *Schema) generateRow(g *dataset.Generator) (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(s.order))
+	for _, k := range s.order {
+		v, err := s.generateField(g, k, row)
+		if err != nil {
+			return nil, err
+		}
+		row[k] = v
+	}
+	return row, nil
+}
+
+// Anonymize returns a copy of row of which the fields are regenerated, other
+// values and the fields which the schema does not define are copied as is.
+// Fields depending on regenerated fields, such as templates, are regenerated as
+// well, so that they do not reveal the original values. Regenerated values are
+// not checked against the primary key and unique keys.
+func (s *Schema) Anonymize(row map[string]interface{}, fields []string) (map[string]interface{}, error) {
+	return s.AnonymizeWith(dataset.Default(), row, fields)
+}
+
+// AnonymizeWith anonymizes row from the generator g.
+func (s *Schema) AnonymizeWith(g *dataset.Generator, row map[string]interface{}, fields []string) (map[string]interface{}, error) {
+	regenerate := make(map[string]bool, len(fields))
+	for _, k := range fields {
+		regenerate[k] = true
+	}
+
+	anonymized := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		anonymized[k] = v
+	}
+
+	for _, k := range s.order {
+		for _, dep := range s.Fields[k].Dependencies() {
+			if regenerate[dep] {
+				regenerate[k] = true
+			}
+		}
+		if !regenerate[k] {
+			continue
+		}
+		v, err := s.generateField(g, k, anonymized)
+		if err != nil {
+			return nil, err
+		}
+		anonymized[k] = v
+	}
+	return anonymized, nil
+}
+
+// generateField generates a value for the field k of row, of which the fields k
+// depends on are generated. The value is drawn from the generator derived from
+// g by the table and field names, so that adding, removing or reordering other
+// fields leaves the values of the field unchanged.
+func (s *Schema) generateField(g *dataset.Generator, k string, row map[string]interface{}) (interface{}, error) {
+	f := s.Fields[k]
+	g = g.Derive(s.generators[k])
+	var v interface{}
+	var err error
+	switch {
+	case f.Type == FieldTypeSlug:
+		v, err = f.generate(g, row)
+		if slug, ok := v.(string); ok && s.isUnique(k) {
+			v = s.uniqueSuffix(k, slug, "-", 0)
+		}
+	case f.Type == FieldTypeUsername:
+		v, err = f.generate(g, row)
+		if name, ok := v.(string); ok && s.isUnique(k) {
+			v = s.uniqueSuffix(k, name, "_", f.Username.MaxLength)
+		}
+	case !f.stateful():
+		v, err = f.generate(g, row)
+	case g.Nullable(f.NullableRate):
+		// NULL foreign key, sequence, snowflake or time series
+		v = Null{}
+	case f.UseDefaultRate > 0 && g.Skip(f.UseDefaultRate):
+		v, err = f.defaultValue()
+	case f.Type == FieldTypeSequence:
+		v = s.nextSequence(k, f)
+	case f.Type == FieldTypeSnowflake:
+		v = s.nextSnowflake(k, f)
+	case f.Type == FieldTypeDateTime:
+		v = f.formatTime(s.nextTime(g, k, f))
+	default:
+		v, err = s.pickReference(g, f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generate field %q: %s", k, err)
+	}
+	return v, nil
+}
+
+// Stream generates count rows one at a time onto the rows channel, which is
+// closed when all rows are generated, ctx is done or a row fails to generate.
+// The error channel then receives the error if any and is closed.
+func (s *Schema) Stream(ctx context.Context, count int) (<-chan map[string]interface{}, <-chan error) {
+	rows := make(chan map[string]interface{})
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(rows)
+
+		for i := 0; i < count; i++ {
+			if err := ctx.Err(); err != nil {
+				errc <- err
+				return
+			}
+			row, err := s.GenerateRow()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case rows <- row:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+			s.progress(i+1, count)
+		}
+	}()
+
+	return rows, errc
+}
+
+// nextSequence returns the next value of the sequence field k.
+func (s *Schema) nextSequence(k string, f Field) int64 {
+	start, step := f.Sequence.Start, f.Sequence.Step
+	if start == 0 {
+		start = 1
+	}
+	if step == 0 {
+		step = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.sequences[k]
+	s.sequences[k]++
+	return start + n*step
+}
+
+// nextSnowflake returns the next id of the snowflake field k, which is greater
+// than the ids generated before even when the clock goes back.
+func (s *Schema) nextSnowflake(k string, f Field) int64 {
+	epoch, _ := f.snowflakeEpoch()
+	ms := int64(time.Since(epoch) / time.Millisecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := s.snowflakes[k]
+	var seq int64
+	if ms <= last[0] {
+		ms, seq = last[0], last[1]+1
+		if seq == 1<<dataset.SnowflakeSequenceBits {
+			ms, seq = ms+1, 0
+		}
+	}
+	s.snowflakes[k] = [2]int64{ms, seq}
+	return dataset.Snowflake(ms, f.Snowflake.MachineID, seq)
+}
+
+// nextTime returns the next time of the monotonic datetime field k, Min for
+// the first row.
+func (s *Schema) nextTime(g *dataset.Generator, k string, f Field) time.Time {
+	interval, jitter, _ := f.monotonicStep()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, found := s.times[k]
+	if !found {
+		t, _, _ = f.dateTimeRange()
+	} else {
+		step := interval
+		if jitter > 0 {
+			resolution := time.Second
+			for jitter%resolution != 0 {
+				resolution /= 1000
+			}
+			step += g.Duration(-jitter, jitter, resolution)
+		}
+		t = t.Add(step)
+	}
+	s.times[k] = t
+	return t
+}
+
+// stateful reports whether the values of the field are generated from the state
+// of the schema, which is left to generateRow.
+func (f Field) stateful() bool {
+	switch f.Type {
+	case FieldTypeForeignKey, FieldTypeSequence, FieldTypeSnowflake:
+		return true
+	case FieldTypeDateTime:
+		return f.DateTime.Monotonic
+	default:
+		return false
+	}
+}
+
+// GenerateParallel generates count rows across workers goroutines, or one per
+// CPU when workers is not positive. Each worker generates from its own
+// generator, seeded from the default one, so rows are reproducible with
+// dataset.Seed while their order on the channel is not. The rows channel is
+// closed when all rows are generated, ctx is done or a row fails to generate,
+// the error channel then receives the error if any and is closed.
+func (s *Schema) GenerateParallel(ctx context.Context, count, workers int) (<-chan map[string]interface{}, <-chan error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	rows := make(chan map[string]interface{}, workers)
+	errc := make(chan error, 1)
+
+	var generated int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		n := count / workers
+		if i < count%workers {
+			n++
+		}
+		g := dataset.New(dataset.Int63())
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				err := ctx.Err()
+				var row map[string]interface{}
+				if err == nil {
+					row, err = s.GenerateRowWith(g)
+				}
+				if err == nil {
+					select {
+					case rows <- row:
+						s.progress(int(atomic.AddInt64(&generated, 1)), count)
+						continue
+					case <-ctx.Done():
+						err = ctx.Err()
+					}
+				}
+				select {
+				case errc <- err:
+				default:
+				}
+				cancel()
+				return
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(rows)
+		close(errc)
+	}()
+
+	return rows, errc
+}
+
+// Dependencies returns the keys of the fields which have to be generated before
+// the field, those of templates in the order they appear in the expression.
+func (f Field) Dependencies() []string {
+	switch f.Type {
+	case FieldTypeTemplate:
+		var deps []string
+		for _, m := range templatePlaceholder.FindAllStringSubmatch(f.Template.Expr, -1) {
+			deps = append(deps, m[1])
+		}
+		return deps
+	case FieldTypeDateTime:
+		if f.DateTime.After != "" {
+			return []string{f.DateTime.After}
+		}
+		return nil
+	case FieldTypeName:
+		if f.Name.Gender != "" {
+			return []string{f.Name.Gender}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// generationOrder sorts the keys of fields topologically by their dependencies.
+func generationOrder(fields map[string]Field) ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	order := make([]string, 0, len(fields))
+	states := make(map[string]int, len(fields))
+
+	var visit func(k string, path []string) error
+	visit = func(k string, path []string) error {
+		switch states[k] {
+		case visiting:
+			return fmt.Errorf("cyclic dependency between fields: %s", strings.Join(append(path, k), " -> "))
+		case visited:
+			return nil
+		}
+
+		states[k] = visiting
+		for _, dep := range fields[k].Dependencies() {
+			d, found := fields[dep]
+			if !found {
+				return fmt.Errorf("field %q refers to undefined field %q", k, dep)
+			}
+			if fields[k].Type == FieldTypeDateTime && (d.Type != FieldTypeDate && d.Type != FieldTypeDateTime || d.TimeFormat() != "") {
+				return fmt.Errorf("field %q should be after a date or datetime field without format, got %s field %q", k, d.Type, dep)
+			}
+			if fields[k].Type == FieldTypeName && d.Type != FieldTypeGender {
+				return fmt.Errorf("field %q should agree with a gender field, got %s field %q", k, d.Type, dep)
+			}
+			if err := visit(dep, append(path, k)); err != nil {
+				return err
+			}
+		}
+		states[k] = visited
+		order = append(order, k)
+
+		return nil
+	}
+
+	for _, k := range KeysFromFields(fields) {
+		if err := visit(k, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+func renderTemplate(expr string, row map[string]interface{}) string {
+	return templatePlaceholder.ReplaceAllStringFunc(expr, func(placeholder string) string {
+		v := row[placeholder[1:len(placeholder)-1]]
+		if IsNull(v) {
+			return ""
+		}
+		return fmt.Sprint(v)
+	})
+}
@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/luncj/mess/dataset"
+)
+
+func nullableSchema(t *testing.T) *Schema {
+	t.Helper()
+	s, err := NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddString("s", 1, 10).
+		Nullable("s", 30).
+		AddEnum("e", "a", "b", "c").
+		Nullable("e", 50).
+		AddDateTime("created_at").
+		Nullable("created_at", 10).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGenerateRowsSeeded(t *testing.T) {
+	generate := func() []map[string]interface{} {
+		dataset.Seed(7)
+		rows, err := nullableSchema(t).GenerateRows(200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+
+	first, second := generate(), generate()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("rows generated with the same seed differ")
+	}
+
+	nulls := 0
+	for _, row := range first {
+		for _, v := range row {
+			if IsNull(v) {
+				nulls++
+			}
+		}
+	}
+	if nulls == 0 {
+		t.Fatal("got no NULL values")
+	}
+}
+
+func TestAnonymizeDependents(t *testing.T) {
+	contact := Field{Type: FieldTypeTemplate}
+	contact.Template.Expr = "mailto:{email}"
+	s, err := NewBuilder("t").
+		AddInt("id", 1, 1_000_000).
+		PrimaryKey("id").
+		AddField("email", Field{Type: FieldTypeEmail}).
+		AddField("contact", contact).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := map[string]interface{}{"id": "1", "email": "alice@example.com", "contact": "mailto:alice@example.com", "extra": "x"}
+	anonymized, err := s.AnonymizeWith(dataset.New(7), row, []string{"email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anonymized["email"] == row["email"] {
+		t.Error("email was not regenerated")
+	}
+	if want := "mailto:" + anonymized["email"].(string); anonymized["contact"] != want {
+		t.Errorf("got contact %v, want %s", anonymized["contact"], want)
+	}
+	if anonymized["id"] != row["id"] || anonymized["extra"] != row["extra"] {
+		t.Errorf("got %v, want id and extra copied as is", anonymized)
+	}
+
+	again, err := s.AnonymizeWith(dataset.New(7), row, []string{"email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(anonymized, again) {
+		t.Errorf("rows anonymized with the same seed differ: %v and %v", anonymized, again)
+	}
+}
+
+func TestGenerateRowsFieldAdded(t *testing.T) {
+	generate := func(b *Builder) []map[string]interface{} {
+		s, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataset.Seed(7)
+		rows, err := s.GenerateRows(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+	builder := func() *Builder {
+		return NewBuilder("t").
+			AddInt("id", 1, 1_000_000).
+			PrimaryKey("id").
+			AddString("s", 1, 10).
+			Nullable("s", 30).
+			AddEnum("e", "a", "b", "c")
+	}
+
+	before := generate(builder())
+	after := generate(builder().AddUUID("added").AddBool("b"))
+	for i := range before {
+		for _, k := range []string{"id", "s", "e"} {
+			if !reflect.DeepEqual(before[i][k], after[i][k]) {
+				t.Errorf("row %d: %s changed from %v to %v after adding fields", i, k, before[i][k], after[i][k])
+			}
+		}
+	}
+}
+
+func BenchmarkGenerateRow(b *testing.B) {
+	s, err := NewBuilder("t").
+		AddInt("id", 1, 1<<62).
+		PrimaryKey("id").
+		AddString("s", 1, 10).
+		AddEnum("e", "a", "b", "c").
+		AddBool("b").
+		AddUUID("u").
+		AddDateTime("created_at").
+		Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+	g := dataset.New(7)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.GenerateRowWith(g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,28 +1,91 @@
 package schema
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/luncj/mess/dataset"
+	"io/ioutil"
+	"math"
 	"math/big"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ghodss/yaml"
 )
 
 type FieldType string
 
 const (
-	FieldTypeInt      FieldType = "int"
-	FieldTypeFloat    FieldType = "float"
-	FieldTypeString   FieldType = "string"
-	FieldTypeJSON     FieldType = "json"
-	FieldTypeDate     FieldType = "date"
-	FieldTypeDateTime FieldType = "datetime"
-	FieldTypeTime     FieldType = "time"
-	FieldTypeEnum     FieldType = "enum"
-	FieldTypeSet      FieldType = "set"
+	FieldTypeInt          FieldType = "int"
+	FieldTypeFloat        FieldType = "float"
+	FieldTypeString       FieldType = "string"
+	FieldTypeJSON         FieldType = "json"
+	FieldTypeDate         FieldType = "date"
+	FieldTypeDateTime     FieldType = "datetime"
+	FieldTypeTime         FieldType = "time"
+	FieldTypeEnum         FieldType = "enum"
+	FieldTypeSet          FieldType = "set"
+	FieldTypeBool         FieldType = "bool"
+	FieldTypeUUID         FieldType = "uuid"
+	FieldTypeDecimal      FieldType = "decimal"
+	FieldTypeEmail        FieldType = "email"
+	FieldTypePhone        FieldType = "phone"
+	FieldTypeName         FieldType = "name"
+	FieldTypeAddress      FieldType = "address"
+	FieldTypeURL          FieldType = "url"
+	FieldTypeIPv4         FieldType = "ipv4"
+	FieldTypeIPv6         FieldType = "ipv6"
+	FieldTypeMAC          FieldType = "mac"
+	FieldTypeColor        FieldType = "color"
+	FieldTypeGeo          FieldType = "geo"
+	FieldTypeRegex        FieldType = "regex"
+	FieldTypeTemplate     FieldType = "template"
+	FieldTypeForeignKey   FieldType = "foreign_key"
+	FieldTypeBinary       FieldType = "binary"
+	FieldTypeArray        FieldType = "array"
+	FieldTypeObject       FieldType = "object"
+	FieldTypeSequence     FieldType = "sequence"
+	FieldTypeConst        FieldType = "const"
+	FieldTypePercent      FieldType = "percent"
+	FieldTypeULID         FieldType = "ulid"
+	FieldTypeSnowflake    FieldType = "snowflake"
+	FieldTypeSlug         FieldType = "slug"
+	FieldTypeMoney        FieldType = "money"
+	FieldTypeCountry      FieldType = "country"
+	FieldTypeCurrencyCode FieldType = "currency_code"
+	FieldTypeUsername     FieldType = "username"
+	FieldTypeBase64       FieldType = "base64"
+	FieldTypeHex          FieldType = "hex"
+	FieldTypeAge          FieldType = "age"
+	FieldTypeGender       FieldType = "gender"
+	FieldTypeDuration     FieldType = "duration"
+	FieldTypePath         FieldType = "path"
+	FieldTypeCompany      FieldType = "company"
 )
 
+var fieldTypes = []FieldType{
+	FieldTypeInt, FieldTypeFloat, FieldTypeString, FieldTypeJSON, FieldTypeDate, FieldTypeDateTime, FieldTypeTime,
+	FieldTypeEnum, FieldTypeSet, FieldTypeBool, FieldTypeUUID, FieldTypeDecimal, FieldTypeEmail, FieldTypePhone,
+	FieldTypeName, FieldTypeAddress, FieldTypeURL, FieldTypeIPv4, FieldTypeIPv6, FieldTypeMAC, FieldTypeColor,
+	FieldTypeGeo, FieldTypeRegex, FieldTypeTemplate, FieldTypeForeignKey, FieldTypeBinary, FieldTypeArray,
+	FieldTypeObject, FieldTypeSequence, FieldTypeConst, FieldTypePercent,
+	FieldTypeULID, FieldTypeSnowflake, FieldTypeSlug, FieldTypeMoney,
+	FieldTypeCountry, FieldTypeCurrencyCode, FieldTypeUsername,
+	FieldTypeBase64, FieldTypeHex, FieldTypeAge, FieldTypeGender,
+	FieldTypeDuration, FieldTypePath, FieldTypeCompany,
+}
+
 type StringType string
 
 const (
@@ -30,27 +93,110 @@ const (
 	StringTypeWord      StringType = "word"
 	StringTypeSentence  StringType = "sentence"
 	StringTypeParagraph StringType = "paragraph"
+	StringTypeUnicode   StringType = "unicode"
+)
+
+const (
+	GeoPartLat   = "lat"
+	GeoPartLng   = "lng"
+	GeoPartPoint = "point"
+)
+
+const defaultGeoPrecision = 6
+
+const defaultSlugWords = 3
+
+const defaultPathDepth = 2
+
+const defaultAgeMax = 100
+
+const (
+	DurationUnitSeconds      = "seconds"
+	DurationUnitMilliseconds = "ms"
+)
+
+const defaultDurationMax = 24 * time.Hour
+
+const (
+	defaultFloatPrecision = 10
+	defaultFloatScale     = 2
+)
+
+const (
+	TimeFormatEpoch       = "epoch"
+	TimeFormatEpochMillis = "epoch_ms"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04:05"
+)
+
+type SetFormat string
+
+const (
+	SetFormatCSV  SetFormat = "csv"
+	SetFormatJSON SetFormat = "json"
+)
+
+type BinaryEncoding string
+
+const (
+	BinaryEncodingRaw    BinaryEncoding = "raw"
+	BinaryEncodingHex    BinaryEncoding = "hex"
+	BinaryEncodingBase64 BinaryEncoding = "base64"
 )
 
 type Field struct {
 	NullableRate int       `json:"nullable_rate"`
 	Type         FieldType `json:"type"`
+	// Default is generated instead of a value of the type at UseDefaultRate
+	// percent of the values which are not NULL per NullableRate: a value is
+	// NULL first, then the default, and otherwise generated. Default is
+	// decoded into the type of the generated values, e.g. a date field takes
+	// "2006-01-02" and a float field takes 0, and null makes it NULL.
+	Default        json.RawMessage `json:"default,omitempty"`
+	UseDefaultRate int             `json:"use_default_rate,omitempty"`
 
 	Int struct {
-		Min *big.Int `json:"min"`
-		Max *big.Int `json:"max"`
+		Min          *big.Int `json:"min"`
+		Max          *big.Int `json:"max"`
+		Distribution string   `json:"distribution"`
+		Mean         float64  `json:"mean"`
+		StdDev       float64  `json:"std_dev"`
+		Step         int64    `json:"step"`
+		// Unsigned floors Min at 0.
+		Unsigned bool `json:"unsigned"`
+		// ExclusiveMin and ExclusiveMax exclude Min and Max from the range.
+		ExclusiveMin bool `json:"exclusive_min"`
+		ExclusiveMax bool `json:"exclusive_max"`
 	} `json:"int"`
 
 	Float struct {
-		Precision int `json:"precision"`
-		Scale     int `json:"scale"`
+		Precision int      `json:"precision"`
+		Scale     int      `json:"scale"`
+		Min       *float64 `json:"min"`
+		Max       *float64 `json:"max"`
+		// ExclusiveMin and ExclusiveMax exclude Min and Max from the range, the
+		// generated value is at least a unit of Scale away from them. Min and
+		// Max have at most Precision-Scale integer digits.
+		ExclusiveMin bool `json:"exclusive_min"`
+		ExclusiveMax bool `json:"exclusive_max"`
 	} `json:"float"`
 
 	String struct {
-		Type  StringType `json:"type"`
+		Type StringType `json:"type"`
+		// Prefix and Suffix are added after the generated string is left-padded
+		// with PadChar, "0" by default, up to PadTo characters.
+		Prefix  string `json:"prefix"`
+		Suffix  string `json:"suffix"`
+		PadTo   int    `json:"pad_to"`
+		PadChar string `json:"pad_char"`
+
 		Ascii struct {
-			MinLength int `json:"min_length"`
-			MaxLength int `json:"max_length"`
+			MinLength int    `json:"min_length"`
+			MaxLength int    `json:"max_length"`
+			Charset   string `json:"charset"`
 		} `json:"ascii"`
 		Paragraph struct {
 			Num int `json:"num"`
@@ -61,28 +207,284 @@ type Field struct {
 		Word struct {
 			Num int `json:"num"`
 		} `json:"word"`
+		// Unicode draws runes of the named blocks, latin-extended by default.
+		Unicode struct {
+			MinLength int      `json:"min_length"`
+			MaxLength int      `json:"max_length"`
+			Blocks    []string `json:"blocks"`
+		} `json:"unicode"`
 	} `json:"string"`
 
+	// JSON generates an arbitrary document when MaxDepth and Keys are both zero.
+	// Arrays of the document have [ArrayMin, ArrayMax] items when ArrayMax is
+	// not zero, otherwise Keys items.
 	JSON struct {
-		Num int `json:"num"`
+		MaxDepth int `json:"max_depth"`
+		Keys     int `json:"keys"`
+		ArrayMin int `json:"array_min"`
+		ArrayMax int `json:"array_max"`
 	} `json:"json"`
 
 	Date struct {
+		Min    string `json:"min"`
+		Max    string `json:"max"`
+		Format string `json:"format"`
 	} `json:"date"`
 
 	Time struct {
+		Min    string `json:"min"`
+		Max    string `json:"max"`
+		Format string `json:"format"`
 	} `json:"time"`
 
 	DateTime struct {
+		Min      string `json:"min"`
+		Max      string `json:"max"`
+		Timezone string `json:"timezone"`
+		Format   string `json:"format"`
+		// After names a date or datetime field of the row which the generated
+		// value should not be before.
+		After string `json:"after"`
+		// BusinessHours biases the generated values to 9:00 - 17:00 of Weekdays,
+		// Monday to Friday by default, in Timezone. Weekdays alone restricts the
+		// values to the named days, e.g. "saturday".
+		BusinessHours bool     `json:"business_hours"`
+		Weekdays      []string `json:"weekdays"`
+		// Monotonic generates a time series which starts at Min and steps
+		// forward by Interval, give or take a random Jitter shorter than
+		// Interval, every row. Max does not bound the series.
+		Monotonic bool   `json:"monotonic"`
+		Interval  string `json:"interval"`
+		Jitter    string `json:"jitter"`
 	} `json:"datetime"`
 
 	Enum struct {
 		Options []string `json:"options"`
+		Weights []int    `json:"weights"`
+		// OptionsFile names a file of options, a JSON array or one option per
+		// line, which are appended to Options when the schema is read. A
+		// relative path is relative to the schema definition file.
+		OptionsFile string `json:"options_file,omitempty"`
 	} `json:"enum"`
 
 	Set struct {
 		Options []string `json:"options"`
+		Min     int      `json:"min"`
+		// Max defaults to the number of options when it is zero.
+		Max int `json:"max"`
+		// Format defaults to csv, which joins the options with commas like
+		// MySQL, json generates an array of the options.
+		Format SetFormat `json:"format"`
 	} `json:"set"`
+
+	Bool struct {
+		TrueRate int `json:"true_rate"`
+	} `json:"bool"`
+
+	UUID struct {
+		Version int `json:"version"`
+	} `json:"uuid"`
+
+	Decimal struct {
+		Min   *big.Int `json:"min"`
+		Max   *big.Int `json:"max"`
+		Scale int      `json:"scale"`
+	} `json:"decimal"`
+
+	Email struct {
+		Domains []string `json:"domains"`
+	} `json:"email"`
+
+	Phone struct {
+		Format  string `json:"format"`
+		Country string `json:"country"`
+	} `json:"phone"`
+
+	Name struct {
+		Part   string `json:"part"`
+		Locale string `json:"locale"`
+		// Gender names a gender field of the row which the first name agrees
+		// with.
+		Gender string `json:"gender"`
+	} `json:"name"`
+
+	Address struct {
+		Part string `json:"part"`
+	} `json:"address"`
+
+	URL struct {
+		Schemes      []string `json:"schemes"`
+		IncludeQuery bool     `json:"include_query"`
+	} `json:"url"`
+
+	IPv4 struct {
+		CIDR string `json:"cidr"`
+	} `json:"ipv4"`
+
+	MAC struct {
+		Separator string `json:"separator"`
+	} `json:"mac"`
+
+	Color struct {
+		IncludeAlpha bool `json:"include_alpha"`
+	} `json:"color"`
+
+	Geo struct {
+		Part string `json:"part"`
+		// BBox is [min_lng, min_lat, max_lng, max_lat] as in GeoJSON, the whole
+		// globe is used when it is zero.
+		BBox      [4]float64 `json:"bbox"`
+		Precision int        `json:"precision"`
+	} `json:"geo"`
+
+	Regex struct {
+		Pattern string `json:"pattern"`
+	} `json:"regex"`
+
+	Template struct {
+		// Expr refers to other fields of the row by {name}.
+		Expr string `json:"expr"`
+	} `json:"template"`
+
+	ForeignKey struct {
+		Table string `json:"table"`
+		Field string `json:"field"`
+	} `json:"foreign_key"`
+
+	Binary struct {
+		MinLength int `json:"min_length"`
+		MaxLength int `json:"max_length"`
+		// Encoding defaults to raw, which generates []byte.
+		Encoding BinaryEncoding `json:"encoding"`
+	} `json:"binary"`
+
+	Array struct {
+		Element   *Field `json:"element"`
+		MinLength int    `json:"min_length"`
+		MaxLength int    `json:"max_length"`
+	} `json:"array"`
+
+	Object struct {
+		Fields map[string]Field `json:"fields"`
+	} `json:"object"`
+
+	// Sequence generates Start, Start+Step, ... over the rows generated by a
+	// schema, Start and Step default to 1.
+	Sequence struct {
+		Start int64 `json:"start"`
+		Step  int64 `json:"step"`
+	} `json:"sequence"`
+
+	Const struct {
+		Value json.RawMessage `json:"value"`
+	} `json:"const"`
+
+	// Percent generates a value in [0, 100], or in [0, 1] as a fraction, rounded
+	// to Scale decimals.
+	Percent struct {
+		AsFraction bool `json:"as_fraction"`
+		Scale      int  `json:"scale"`
+	} `json:"percent"`
+
+	// ULID draws the timestamp of the ULID within [Min, Max] in RFC 3339.
+	ULID struct {
+		Min string `json:"min"`
+		Max string `json:"max"`
+	} `json:"ulid"`
+
+	// Snowflake generates increasing ids of the milliseconds since Epoch in
+	// RFC 3339, 2010-11-04T01:42:54.657Z by default, over the rows generated
+	// by a schema.
+	Snowflake struct {
+		MachineID int64  `json:"machine_id"`
+		Epoch     string `json:"epoch"`
+	} `json:"snowflake"`
+
+	// Slug joins Words words, 3 by default, by hyphens. Slugs of a primary key
+	// or unique key get a numeric suffix when generated before.
+	Slug struct {
+		Words int `json:"words"`
+	} `json:"slug"`
+
+	// Company generates company names like "Bright Harbor", followed by a
+	// legal suffix like "LLC" when IncludeSuffix.
+	Company struct {
+		IncludeSuffix bool `json:"include_suffix"`
+	} `json:"company"`
+
+	// Path generates file paths like "/uploads/photos/beach.jpg" of Depth
+	// directories, 2 by default, and a file with one of Extensions, or a
+	// common extension when none is given.
+	Path struct {
+		Depth      int      `json:"depth"`
+		Extensions []string `json:"extensions"`
+	} `json:"path"`
+
+	// Money generates amounts of minor units in [Min, Max] with Scale digits
+	// after the decimal point. Currency is the ISO 4217 code of the amounts,
+	// a const field can hold it in its own column.
+	Money struct {
+		Min      int64  `json:"min"`
+		Max      int64  `json:"max"`
+		Currency string `json:"currency"`
+		Scale    int    `json:"scale"`
+	} `json:"money"`
+
+	// Country generates ISO 3166-1 codes in Format, alpha2 by default.
+	Country struct {
+		Format string `json:"format"`
+	} `json:"country"`
+
+	// CurrencyCode generates ISO 4217 codes, only of Only when given.
+	CurrencyCode struct {
+		Only []string `json:"only"`
+	} `json:"currency_code"`
+
+	// Username generates handles of lowercase words like "cool_fox_42", of
+	// at most MaxLength characters when set. Usernames of a primary key or
+	// unique key get a numeric suffix when generated before.
+	Username struct {
+		MaxLength   int  `json:"max_length"`
+		AllowDigits bool `json:"allow_digits"`
+	} `json:"username"`
+
+	// Base64 encodes [MinBytes, MaxBytes] random bytes in standard base64, or
+	// URL-safe base64 when URLSafe.
+	Base64 struct {
+		MinBytes int  `json:"min_bytes"`
+		MaxBytes int  `json:"max_bytes"`
+		URLSafe  bool `json:"url_safe"`
+	} `json:"base64"`
+
+	// Hex generates strings of Length hex digits, in uppercase when Upper.
+	Hex struct {
+		Length int  `json:"length"`
+		Upper  bool `json:"upper"`
+	} `json:"hex"`
+
+	// Age generates ages in [Min, Max], [0, 100] by default, drawn from
+	// Distribution, adult by default.
+	Age struct {
+		Min int `json:"min"`
+		// Max defaults to 100 when it is zero.
+		Max          int    `json:"max"`
+		Distribution string `json:"distribution"`
+	} `json:"age"`
+
+	// Gender generates male or female, or other at OtherRate percent.
+	Gender struct {
+		OtherRate int `json:"other_rate"`
+	} `json:"gender"`
+
+	// Duration generates durations within [Min, Max], 0s and 24h by default,
+	// parsed by time.ParseDuration. The values are integer counts of Unit,
+	// seconds or ms, or duration strings like "1h30m" of whole seconds, or
+	// milliseconds when a bound has them, when Unit is empty.
+	Duration struct {
+		Min  string `json:"min"`
+		Max  string `json:"max"`
+		Unit string `json:"unit"`
+	} `json:"duration"`
 }
 
 type Schema struct {
@@ -90,9 +492,34 @@ type Schema struct {
 	PrimaryKeys []string         `json:"primary_keys"`
 	UniqueKeys  [][]string       `json:"unique_keys"`
 	Fields      map[string]Field `json:"fields"`
+	// MaxRetries limits the attempts to generate a row with a unique primary
+	// key and unique keys, defaults to 100.
+	MaxRetries int `json:"max_retries"`
+	// Progress is called with the number of rows generated so far by
+	// GenerateRows, Stream and GenerateParallel every ProgressInterval rows,
+	// 1000 by default, and after the last row. GenerateParallel calls it from
+	// its workers concurrently.
+	Progress         func(generated, total int) `json:"-"`
+	ProgressInterval int                        `json:"-"`
 
-	keys        []string
+	keys  []string
+	order []string
+	// generators holds the names of the generators of the fields, derived
+	// from the generator of the rows.
+	generators  map[string]string
 	primaryKeys map[string]bool
+	references  map[string][]interface{}
+
+	mu        sync.Mutex
+	generated []map[string]bool
+	sequences map[string]int64
+	// snowflakes holds the last millisecond and sequence number of snowflake
+	// fields.
+	snowflakes map[string][2]int64
+	// suffixes counts the values generated of unique slug and username fields.
+	suffixes map[string]map[string]int
+	// times holds the last time of monotonic datetime fields.
+	times map[string]time.Time
 }
 
 func FromFile(path string) (*Schema, error) {
@@ -103,14 +530,126 @@ func FromFile(path string) (*Schema, error) {
 	}
 	defer f.Close()
 
-	if err := json.NewDecoder(f).Decode(&s); err != nil {
-		return nil, fmt.Errorf("read schema: %s", err)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		b, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("read schema: %s", err)
+		}
+		if err := yaml.Unmarshal(b, &s); err != nil {
+			return nil, fmt.Errorf("read schema: %s", err)
+		}
+	default:
+		if err := json.NewDecoder(f).Decode(&s); err != nil {
+			return nil, fmt.Errorf("read schema: %s", err)
+		}
 	}
 
-	if err := s.validate(); err != nil {
+	for k, field := range s.Fields {
+		if file := field.Enum.OptionsFile; file != "" && !filepath.IsAbs(file) {
+			field.Enum.OptionsFile = filepath.Join(filepath.Dir(path), file)
+			s.Fields[k] = field
+		}
+	}
+
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// loadOptionsFiles appends the options of the options files of enum fields to
+// their options.
+func (s *Schema) loadOptionsFiles() error {
+	for k, f := range s.Fields {
+		if f.Type != FieldTypeEnum || f.Enum.OptionsFile == "" {
+			continue
+		}
+		options, err := readOptionsFile(f.Enum.OptionsFile)
+		if err != nil {
+			return fmt.Errorf("invalid field %q: %s", k, err)
+		}
+		f.Enum.Options = append(f.Enum.Options, options...)
+		f.Enum.OptionsFile = ""
+		s.Fields[k] = f
+	}
+	return nil
+}
+
+func readOptionsFile(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read enum options file: %s", err)
+	}
+
+	var options []string
+	if content := bytes.TrimSpace(b); bytes.HasPrefix(content, []byte("[")) {
+		if err := json.Unmarshal(content, &options); err != nil {
+			return nil, fmt.Errorf("decode enum options file %q: %s", path, err)
+		}
+	} else {
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				options = append(options, line)
+			}
+		}
+	}
+	if len(options) == 0 {
+		return nil, fmt.Errorf("enum options file %q should not be empty", path)
+	}
+	return options, nil
+}
+
+// ToFile writes the schema as an indented JSON file, which FromFile reads back
+// as an equivalent schema.
+func (s *Schema) ToFile(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schema: %s", err)
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("write schema definition file: %s", err)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the exported fields of the schema, fields are sorted by
+// their keys.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	return json.Marshal((*schema)(s))
+}
+
+// MarshalJSON encodes the field with only the options of its own type.
+func (f Field) MarshalJSON() ([]byte, error) {
+	type field Field
+	b, err := json.Marshal(field(f))
+	if err != nil {
 		return nil, err
 	}
 
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for _, t := range fieldTypes {
+		if t != f.Type {
+			delete(m, string(t))
+		}
+	}
+	return json.Marshal(m)
+}
+
+// prepare validates the schema and sets it up for generation.
+func (s *Schema) prepare() error {
+	if err := s.loadOptionsFiles(); err != nil {
+		return err
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+
 	sort.Strings(s.PrimaryKeys)
 
 	for i := range s.UniqueKeys {
@@ -118,12 +657,25 @@ func FromFile(path string) (*Schema, error) {
 	}
 
 	s.keys = KeysFromFields(s.Fields)
+	s.order, _ = generationOrder(s.Fields)
+	s.generators = make(map[string]string, len(s.Fields))
+	for k := range s.Fields {
+		s.generators[k] = fmt.Sprintf("%s.%s", s.Table, k)
+	}
 	s.primaryKeys = make(map[string]bool, len(s.PrimaryKeys))
 	for _, k := range s.PrimaryKeys {
 		s.primaryKeys[k] = true
 	}
+	s.sequences = make(map[string]int64)
+	s.snowflakes = make(map[string][2]int64)
+	s.suffixes = make(map[string]map[string]int)
+	s.times = make(map[string]time.Time)
+	s.generated = make([]map[string]bool, len(s.uniqueKeys()))
+	for i := range s.generated {
+		s.generated[i] = make(map[string]bool)
+	}
 
-	return &s, nil
+	return nil
 }
 
 func (s *Schema) validate() error {
@@ -136,11 +688,882 @@ func (s *Schema) validate() error {
 		if _, found := s.Fields[pk]; !found {
 			return fmt.Errorf("primary keys %q is not defined in fields", pk)
 		}
+		if rate := s.Fields[pk].NullableRate; rate != 0 {
+			return fmt.Errorf("primary keys %q should not be nullable, got nullable rate %d", pk, rate)
+		}
+		if s.Fields[pk].IsNullable() {
+			return fmt.Errorf("primary keys %q should not default to null", pk)
+		}
+	}
+
+	for i, uk := range s.UniqueKeys {
+		if len(uk) == 0 {
+			return fmt.Errorf("unique key #%d should not be empty", i+1)
+		}
+		for _, k := range uk {
+			if _, found := s.Fields[k]; !found {
+				return fmt.Errorf("unique key (%s) refers to %q which is not defined in fields", strings.Join(uk, ", "), k)
+			}
+		}
+	}
+
+	for _, k := range KeysFromFields(s.Fields) {
+		if err := s.Fields[k].validate(); err != nil {
+			return fmt.Errorf("invalid field %q: %s", k, err)
+		}
+	}
+
+	if _, err := generationOrder(s.Fields); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+func (f Field) validate() error {
+	if !f.Type.isBuiltIn() {
+		if _, found := registeredType(f.Type); !found {
+			return fmt.Errorf("unsupported field type %q", f.Type)
+		}
+	}
+	if f.NullableRate < 0 || f.NullableRate > 100 {
+		return fmt.Errorf("invalid nullable rate %d, required 0 <= nullable_rate <= 100", f.NullableRate)
+	}
+	if f.UseDefaultRate < 0 || f.UseDefaultRate > 100 {
+		return fmt.Errorf("invalid use default rate %d, required 0 <= use_default_rate <= 100", f.UseDefaultRate)
+	}
+	if f.UseDefaultRate > 0 {
+		if len(f.Default) == 0 {
+			return fmt.Errorf("default value is required by use_default_rate")
+		}
+		if _, err := f.defaultValue(); err != nil {
+			return err
+		}
+	}
+
+	switch f.Type {
+	case FieldTypeInt:
+		switch f.Int.Distribution {
+		case "", dataset.DistributionUniform, dataset.DistributionNormal, dataset.DistributionExponential:
+		default:
+			return fmt.Errorf("invalid int distribution %q, required (%q / %q / %q)", f.Int.Distribution,
+				dataset.DistributionUniform, dataset.DistributionNormal, dataset.DistributionExponential)
+		}
+		if f.Int.StdDev < 0 {
+			return fmt.Errorf("int std_dev should not be negative, got %v", f.Int.StdDev)
+		}
+		if f.Int.Step < 0 {
+			return fmt.Errorf("int step should not be negative, got %d", f.Int.Step)
+		}
+		if f.Int.Unsigned && f.Int.Max != nil && f.Int.Max.Sign() < 0 {
+			return fmt.Errorf("unsigned int max should not be negative, got %s", f.Int.Max)
+		}
+		if min, max := f.IntRange(); min != nil && max != nil && min.Cmp(max) > 0 {
+			return fmt.Errorf("int range [%s, %s] is empty", min, max)
+		}
+		if min, max := f.IntRange(); f.Int.Step > 0 && min != nil && max != nil {
+			if m := dataset.Multiple(min, min, f.Int.Step); m.Cmp(max) > 0 {
+				return fmt.Errorf("no multiple of step %d in range [%s, %s]", f.Int.Step, min, max)
+			}
+		}
+	case FieldTypeFloat:
+		if p, sc := f.Float.Precision, f.Float.Scale; p < 0 || sc < 0 || sc > p {
+			return fmt.Errorf("invalid float precision %d and scale %d, required 0 <= scale <= precision", p, sc)
+		}
+		if f.Float.Min != nil && f.Float.Max != nil && *f.Float.Min > *f.Float.Max {
+			return fmt.Errorf("float min %v should not be greater than max %v", *f.Float.Min, *f.Float.Max)
+		}
+		precision, scale := f.floatPrecision()
+		limit := math.Pow10(precision-scale) - math.Pow10(-scale)
+		for _, bound := range []*float64{f.Float.Min, f.Float.Max} {
+			if bound != nil && math.Abs(*bound) > limit {
+				return fmt.Errorf("float bound %v does not fit in precision %d and scale %d, required within [%v, %v]", *bound, precision, scale, -limit, limit)
+			}
+		}
+		if min, max, _ := f.floatRange(); min > max {
+			return fmt.Errorf("float range [%v, %v] is empty at scale %d", min, max, scale)
+		}
+	case FieldTypeDate, FieldTypeDateTime, FieldTypeTime:
+		min, max, err := f.dateTimeRange()
+		if err != nil {
+			return err
+		}
+		if min.After(max) {
+			return fmt.Errorf("%s min %s should not be after max %s", f.Type, min, max)
+		}
+		if _, err := f.weekdays(); err != nil {
+			return err
+		}
+		if f.Type == FieldTypeDateTime && f.DateTime.Monotonic {
+			if err := f.validateMonotonic(); err != nil {
+				return err
+			}
+		}
+		if tz := f.DateTime.Timezone; f.Type == FieldTypeDateTime && tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil {
+				return fmt.Errorf("load datetime timezone: %s", err)
+			}
+		}
+		switch layout := f.TimeFormat(); layout {
+		case "", TimeFormatEpoch, TimeFormatEpochMillis:
+		default:
+			ref := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+			if _, err := time.Parse(layout, ref.Format(layout)); err != nil {
+				return fmt.Errorf("invalid %s format %q: %s", f.Type, layout, err)
+			}
+		}
+	case FieldTypeString:
+		if f.String.PadTo < 0 {
+			return fmt.Errorf("string pad_to should not be negative, got %d", f.String.PadTo)
+		}
+		if c := f.String.PadChar; c != "" && utf8.RuneCountInString(c) != 1 {
+			return fmt.Errorf("string pad_char should be a single character, got %q", c)
+		}
+		if f.String.Type != StringTypeUnicode {
+			break
+		}
+		u := f.String.Unicode
+		if u.MinLength < 0 || u.MinLength >= u.MaxLength {
+			return fmt.Errorf("invalid unicode length [%d, %d), required 0 <= min_length < max_length", u.MinLength, u.MaxLength)
+		}
+		for _, b := range u.Blocks {
+			if !dataset.IsUnicodeBlock(b) {
+				return fmt.Errorf("unsupported unicode block %q", b)
+			}
+		}
+	case FieldTypeJSON:
+		j := f.JSON
+		if j.ArrayMin < 0 || j.ArrayMin > j.ArrayMax {
+			return fmt.Errorf("invalid json array length [%d, %d], required 0 <= array_min <= array_max", j.ArrayMin, j.ArrayMax)
+		}
+		if j.MaxDepth == 0 && j.Keys == 0 {
+			if j.ArrayMax != 0 {
+				return fmt.Errorf("json array_max requires max_depth and keys")
+			}
+			break
+		}
+		if j.MaxDepth < 1 {
+			return fmt.Errorf("json max_depth should be at least 1, got %d", j.MaxDepth)
+		}
+		if j.Keys < 0 {
+			return fmt.Errorf("json keys should not be negative, got %d", j.Keys)
+		}
+	case FieldTypeEnum:
+		if len(f.Enum.Options) == 0 {
+			return fmt.Errorf("enum options should not be empty")
+		}
+		if w := f.Enum.Weights; w != nil {
+			if len(w) != len(f.Enum.Options) {
+				return fmt.Errorf("enum weights should match options, got %d weights for %d options", len(w), len(f.Enum.Options))
+			}
+			total := 0
+			for _, n := range w {
+				if n < 0 {
+					return fmt.Errorf("enum weights should not be negative, got %d", n)
+				}
+				total += n
+			}
+			if total == 0 {
+				return fmt.Errorf("enum weights should not be all zero")
+			}
+		}
+	case FieldTypeSet:
+		if len(f.Set.Options) == 0 {
+			return fmt.Errorf("set options should not be empty")
+		}
+		seen := make(map[string]bool, len(f.Set.Options))
+		for _, o := range f.Set.Options {
+			if seen[o] {
+				return fmt.Errorf("duplicated set option %q", o)
+			}
+			seen[o] = true
+		}
+		switch f.Set.Format {
+		case "", SetFormatCSV, SetFormatJSON:
+		default:
+			return fmt.Errorf("invalid set format %q, required (%q / %q)", f.Set.Format, SetFormatCSV, SetFormatJSON)
+		}
+		min, max := f.Set.Min, f.setMax()
+		if min < 0 || min > max || max > len(f.Set.Options) {
+			return fmt.Errorf("invalid set cardinality [%d, %d], required 0 <= min <= max <= %d", min, max, len(f.Set.Options))
+		}
+	case FieldTypeUUID:
+		if v := f.UUID.Version; v != 0 && v != 4 {
+			return fmt.Errorf("unsupported uuid version %d, only version 4 is supported", v)
+		}
+	case FieldTypeDecimal:
+		d := f.Decimal
+		if d.Scale < 0 {
+			return fmt.Errorf("decimal scale should not be negative, got %d", d.Scale)
+		}
+		if d.Min == nil || d.Max == nil {
+			return fmt.Errorf("decimal min and max are required")
+		}
+		if d.Min.Cmp(d.Max) > 0 {
+			return fmt.Errorf("decimal min %s should not be greater than max %s", d.Min, d.Max)
+		}
+	case FieldTypePhone:
+		format := f.Phone.Format
+		if format == "" {
+			var ok bool
+			if format, ok = dataset.PhoneFormat(f.Phone.Country); !ok {
+				return fmt.Errorf("unsupported phone country %q", f.Phone.Country)
+			}
+		}
+		if !strings.Contains(format, "#") {
+			return fmt.Errorf("phone format %q should contain at least one '#'", format)
+		}
+	case FieldTypeName:
+		switch f.Name.Part {
+		case dataset.NamePartFirst, dataset.NamePartLast, dataset.NamePartFull:
+		default:
+			return fmt.Errorf("invalid name part %q, required (%q / %q / %q)", f.Name.Part,
+				dataset.NamePartFirst, dataset.NamePartLast, dataset.NamePartFull)
+		}
+		if !dataset.IsNameLocale(f.Name.Locale) {
+			return fmt.Errorf("unsupported name locale %q", f.Name.Locale)
+		}
+	case FieldTypeAddress:
+		switch f.Address.Part {
+		case dataset.AddressPartStreet, dataset.AddressPartCity, dataset.AddressPartZip, dataset.AddressPartFull:
+		default:
+			return fmt.Errorf("invalid address part %q, required (%q / %q / %q / %q)", f.Address.Part,
+				dataset.AddressPartStreet, dataset.AddressPartCity, dataset.AddressPartZip, dataset.AddressPartFull)
+		}
+	case FieldTypeIPv4:
+		if f.IPv4.CIDR != "" {
+			_, n, err := net.ParseCIDR(f.IPv4.CIDR)
+			if err != nil {
+				return fmt.Errorf("parse ipv4 cidr: %s", err)
+			}
+			if n.IP.To4() == nil {
+				return fmt.Errorf("cidr %q is not an ipv4 network", f.IPv4.CIDR)
+			}
+		}
+	case FieldTypeMAC:
+		switch f.MAC.Separator {
+		case "", ":", "-":
+		default:
+			return fmt.Errorf("invalid mac separator %q, required (%q / %q)", f.MAC.Separator, ":", "-")
+		}
+	case FieldTypeGeo:
+		g := f.Geo
+		switch g.Part {
+		case GeoPartLat, GeoPartLng, GeoPartPoint:
+		default:
+			return fmt.Errorf("invalid geo part %q, required (%q / %q / %q)", g.Part, GeoPartLat, GeoPartLng, GeoPartPoint)
+		}
+		if g.BBox[0] > g.BBox[2] || g.BBox[1] > g.BBox[3] {
+			return fmt.Errorf("invalid geo bbox %v, min should not be greater than max", g.BBox)
+		}
+		if g.Precision < 0 {
+			return fmt.Errorf("geo precision should not be negative, got %d", g.Precision)
+		}
+	case FieldTypeForeignKey:
+		if f.ForeignKey.Table == "" || f.ForeignKey.Field == "" {
+			return fmt.Errorf("foreign key table and field are required")
+		}
+	case FieldTypeULID:
+		min, max, err := f.dateTimeRange()
+		if err != nil {
+			return err
+		}
+		if min.After(max) {
+			return fmt.Errorf("ulid min %s should not be after max %s", min, max)
+		}
+	case FieldTypeSnowflake:
+		if id := f.Snowflake.MachineID; id < 0 || id >= 1<<dataset.SnowflakeMachineBits {
+			return fmt.Errorf("invalid snowflake machine_id %d, required 0 <= machine_id < %d", id, 1<<dataset.SnowflakeMachineBits)
+		}
+		if _, err := f.snowflakeEpoch(); err != nil {
+			return err
+		}
+	case FieldTypeUsername:
+		if f.Username.MaxLength < 0 {
+			return fmt.Errorf("username max_length should not be negative, got %d", f.Username.MaxLength)
+		}
+	case FieldTypeSlug:
+		if f.Slug.Words < 0 {
+			return fmt.Errorf("slug words should not be negative, got %d", f.Slug.Words)
+		}
+	case FieldTypePath:
+		if f.Path.Depth < 0 {
+			return fmt.Errorf("path depth should not be negative, got %d", f.Path.Depth)
+		}
+	case FieldTypeCountry:
+		switch f.Country.Format {
+		case "", dataset.CountryFormatAlpha2, dataset.CountryFormatAlpha3, dataset.CountryFormatNumeric:
+		default:
+			return fmt.Errorf("invalid country format %q, required (%q / %q / %q)", f.Country.Format,
+				dataset.CountryFormatAlpha2, dataset.CountryFormatAlpha3, dataset.CountryFormatNumeric)
+		}
+	case FieldTypeMoney:
+		m := f.Money
+		if m.Scale < 0 {
+			return fmt.Errorf("money scale should not be negative, got %d", m.Scale)
+		}
+		if m.Min > m.Max {
+			return fmt.Errorf("money min %d should not be greater than max %d", m.Min, m.Max)
+		}
+		if c := m.Currency; c != "" && !dataset.IsCurrencyCode(c) {
+			return fmt.Errorf("invalid money currency %q, required an ISO 4217 code", c)
+		}
+	case FieldTypeCurrencyCode:
+		for _, c := range f.CurrencyCode.Only {
+			if !dataset.IsCurrencyCode(c) {
+				return fmt.Errorf("invalid currency code %q, required an ISO 4217 code", c)
+			}
+		}
+	case FieldTypePercent:
+		if f.Percent.Scale < 0 {
+			return fmt.Errorf("percent scale should not be negative, got %d", f.Percent.Scale)
+		}
+	case FieldTypeConst:
+		if len(f.Const.Value) == 0 {
+			return fmt.Errorf("const value is required")
+		}
+		if _, err := f.constValue(); err != nil {
+			return err
+		}
+	case FieldTypeBinary:
+		b := f.Binary
+		if b.MinLength < 0 || b.MinLength > b.MaxLength {
+			return fmt.Errorf("invalid binary length [%d, %d], required 0 <= min_length <= max_length", b.MinLength, b.MaxLength)
+		}
+		switch b.Encoding {
+		case "", BinaryEncodingRaw, BinaryEncodingHex, BinaryEncodingBase64:
+		default:
+			return fmt.Errorf("invalid binary encoding %q, required (%q / %q / %q)", b.Encoding,
+				BinaryEncodingRaw, BinaryEncodingHex, BinaryEncodingBase64)
+		}
+	case FieldTypeGender:
+		if r := f.Gender.OtherRate; r < 0 || r > 100 {
+			return fmt.Errorf("invalid gender other rate %d, required 0 <= other_rate <= 100", r)
+		}
+	case FieldTypeAge:
+		a := f.Age
+		if max := f.AgeMax(); a.Min < 0 || a.Min > max {
+			return fmt.Errorf("invalid age range [%d, %d], required 0 <= min <= max", a.Min, max)
+		}
+		switch a.Distribution {
+		case "", dataset.AgeDistributionAdult, dataset.DistributionUniform, dataset.DistributionNormal:
+		default:
+			return fmt.Errorf("invalid age distribution %q, required (%q / %q / %q)", a.Distribution,
+				dataset.AgeDistributionAdult, dataset.DistributionUniform, dataset.DistributionNormal)
+		}
+	case FieldTypeDuration:
+		if _, _, _, err := f.durationRange(); err != nil {
+			return err
+		}
+	case FieldTypeHex:
+		if f.Hex.Length <= 0 {
+			return fmt.Errorf("hex length should be positive, got %d", f.Hex.Length)
+		}
+	case FieldTypeBase64:
+		b := f.Base64
+		if b.MinBytes < 0 || b.MinBytes > b.MaxBytes {
+			return fmt.Errorf("invalid base64 bytes [%d, %d], required 0 <= min_bytes <= max_bytes", b.MinBytes, b.MaxBytes)
+		}
+	case FieldTypeArray:
+		a := f.Array
+		if a.MinLength < 0 || a.MinLength > a.MaxLength {
+			return fmt.Errorf("invalid array length [%d, %d], required 0 <= min_length <= max_length", a.MinLength, a.MaxLength)
+		}
+		if a.Element == nil {
+			return fmt.Errorf("array element is required")
+		}
+		if a.Element.Type == FieldTypeForeignKey {
+			return fmt.Errorf("array element should not be a foreign key")
+		}
+		if err := a.Element.validate(); err != nil {
+			return fmt.Errorf("invalid array element: %s", err)
+		}
+	case FieldTypeObject:
+		for _, k := range KeysFromFields(f.Object.Fields) {
+			sub := f.Object.Fields[k]
+			if sub.Type == FieldTypeForeignKey {
+				return fmt.Errorf("object field %q should not be a foreign key", k)
+			}
+			if err := sub.validate(); err != nil {
+				return fmt.Errorf("invalid object field %q: %s", k, err)
+			}
+		}
+		if _, err := generationOrder(f.Object.Fields); err != nil {
+			return err
+		}
+	case FieldTypeRegex:
+		if _, err := regexp.Compile(f.Regex.Pattern); err != nil {
+			return fmt.Errorf("compile regex: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// dateTimeRange parses the bounds of date, datetime and time fields, it returns
+// zero times when neither bound is defined.
+func (f Field) dateTimeRange() (time.Time, time.Time, error) {
+	var layout, minValue, maxValue string
+	min, max := dataset.DateTimeBounds()
+
+	switch f.Type {
+	case FieldTypeDate:
+		layout, minValue, maxValue = dateLayout, f.Date.Min, f.Date.Max
+	case FieldTypeDateTime:
+		layout, minValue, maxValue = time.RFC3339, f.DateTime.Min, f.DateTime.Max
+	case FieldTypeULID:
+		layout, minValue, maxValue = time.RFC3339, f.ULID.Min, f.ULID.Max
+	case FieldTypeTime:
+		layout, minValue, maxValue = timeLayout, f.Time.Min, f.Time.Max
+		min, _ = time.Parse(timeLayout, "00:00:00")
+		max, _ = time.Parse(timeLayout, "23:59:59")
+	}
+
+	if minValue == "" && maxValue == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+
+	var err error
+	if minValue != "" {
+		if min, err = time.Parse(layout, minValue); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parse %s min: %s", f.Type, err)
+		}
+	}
+	if maxValue != "" {
+		if max, err = time.Parse(layout, maxValue); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parse %s max: %s", f.Type, err)
+		}
+	}
+
+	return min, max, nil
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// weekdays returns the weekdays of datetime fields, Monday to Friday for
+// business hours by default.
+func (f Field) weekdays() ([]time.Weekday, error) {
+	if len(f.DateTime.Weekdays) == 0 && f.DateTime.BusinessHours {
+		return []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, nil
+	}
+	weekdays := make([]time.Weekday, len(f.DateTime.Weekdays))
+	for i, name := range f.DateTime.Weekdays {
+		d, ok := weekdayNames[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid datetime weekday %q", name)
+		}
+		weekdays[i] = d
+	}
+	return weekdays, nil
+}
+
+// IntRange returns the inclusive range of int fields, of which min is floored
+// at 0 when unsigned.
+func (f Field) IntRange() (min, max *big.Int) {
+	min, max = f.Int.Min, f.Int.Max
+	if f.Int.ExclusiveMin && min != nil {
+		min = new(big.Int).Add(min, big.NewInt(1))
+	}
+	if f.Int.ExclusiveMax && max != nil {
+		max = new(big.Int).Sub(max, big.NewInt(1))
+	}
+	if f.Int.Unsigned && (min == nil || min.Sign() < 0) {
+		min = new(big.Int)
+	}
+	return min, max
+}
+
+// floatRange returns the inclusive range of float fields, bounded reports
+// whether it is narrower than the bounds of the precision and scale.
+func (f Field) floatRange() (min, max float64, bounded bool) {
+	fl := f.Float
+	precision, scale := f.floatPrecision()
+	min, max = dataset.FloatBounds(precision, scale)
+	if fl.Min != nil {
+		min = *fl.Min
+	}
+	if fl.Max != nil {
+		max = *fl.Max
+	}
+	unit := math.Pow10(-scale)
+	if fl.ExclusiveMin {
+		min += unit
+	}
+	if fl.ExclusiveMax {
+		max -= unit
+	}
+	bounded = fl.Min != nil || fl.Max != nil || fl.ExclusiveMin || fl.ExclusiveMax
+	return min, max, bounded
+}
+
+// TimeFormat returns the output format of date, time and datetime fields.
+func (f Field) TimeFormat() string {
+	switch f.Type {
+	case FieldTypeDate:
+		return f.Date.Format
+	case FieldTypeDateTime:
+		return f.DateTime.Format
+	case FieldTypeTime:
+		return f.Time.Format
+	default:
+		return ""
+	}
+}
+
+// Location returns the timezone of datetime fields, or nil when undefined.
+func (f Field) Location() *time.Location {
+	if f.Type != FieldTypeDateTime || f.DateTime.Timezone == "" {
+		return nil
+	}
+	loc, _ := time.LoadLocation(f.DateTime.Timezone)
+	return loc
+}
+
+// floatPrecision returns the precision and scale of a float field, which
+// default to 10 and 2.
+func (f Field) floatPrecision() (int, int) {
+	if f.Float.Precision == 0 && f.Float.Scale == 0 {
+		return defaultFloatPrecision, defaultFloatScale
+	}
+	return f.Float.Precision, f.Float.Scale
+}
+
+// Scale returns the number of decimals of the float values generated for the
+// field, or -1 when they are not rounded.
+func (f Field) Scale() int {
+	switch {
+	case f.Type == FieldTypeFloat:
+		_, scale := f.floatPrecision()
+		return scale
+	case f.Type == FieldTypePercent:
+		return f.Percent.Scale
+	case f.Type == FieldTypeGeo && f.Geo.Part != GeoPartPoint:
+		if f.Geo.Precision == 0 {
+			return defaultGeoPrecision
+		}
+		return f.Geo.Precision
+	default:
+		return -1
+	}
+}
+
+// constValue decodes the value of a const field, integers are decoded as
+// *big.Int like the values of int fields.
+func (f Field) constValue() (interface{}, error) {
+	v, err := decodeValue(f.Const.Value)
+	if err != nil {
+		return nil, fmt.Errorf("decode const value: %s", err)
+	}
+	return v, nil
+}
+
+// defaultValue decodes the default value of the field into the type of its
+// generated values, null into Null.
+func (f Field) defaultValue() (interface{}, error) {
+	v, err := decodeValue(f.Default)
+	if err != nil {
+		return nil, fmt.Errorf("decode default value: %s", err)
+	}
+	if v == nil {
+		return Null{}, nil
+	}
+	converted, ok := f.convertValue(v)
+	if !ok {
+		return nil, fmt.Errorf("invalid default value %s of %s field", f.Default, f.Type)
+	}
+	return converted, nil
+}
+
+// convertValue converts a decoded JSON value to the type of the values the
+// field generates, it reports false when v is not one of them.
+func (f Field) convertValue(v interface{}) (interface{}, bool) {
+	switch f.Type {
+	case FieldTypeInt:
+		i, ok := v.(*big.Int)
+		return i, ok
+	case FieldTypeSequence, FieldTypeSnowflake, FieldTypeAge:
+		return int64Value(v)
+	case FieldTypeDuration:
+		if f.Duration.Unit != "" {
+			return int64Value(v)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		d, err := time.ParseDuration(s)
+		return d.String(), err == nil
+	case FieldTypeFloat, FieldTypePercent:
+		return float64Value(v)
+	case FieldTypeGeo:
+		if f.Geo.Part != GeoPartPoint {
+			return float64Value(v)
+		}
+	case FieldTypeMoney, FieldTypeDecimal:
+		scale := f.Money.Scale
+		if f.Type == FieldTypeDecimal {
+			scale = f.Decimal.Scale
+		}
+		if n, ok := float64Value(v); ok {
+			return strconv.FormatFloat(n.(float64), 'f', scale, 64), true
+		}
+	case FieldTypeBool:
+		b, ok := v.(bool)
+		return b, ok
+	case FieldTypeDate, FieldTypeDateTime, FieldTypeTime:
+		return f.timeValue(v)
+	case FieldTypeBinary:
+		if s, ok := v.(string); ok && (f.Binary.Encoding == "" || f.Binary.Encoding == BinaryEncodingRaw) {
+			return []byte(s), true
+		}
+	case FieldTypeArray:
+		a, ok := v.([]interface{})
+		return a, ok
+	case FieldTypeObject:
+		o, ok := v.(map[string]interface{})
+		return o, ok
+	case FieldTypeSet:
+		if f.Set.Format == SetFormatJSON {
+			a, ok := v.([]interface{})
+			return a, ok
+		}
+	case FieldTypeJSON, FieldTypeConst:
+		return v, true
+	default:
+		if !f.Type.isBuiltIn() {
+			return v, true
+		}
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// timeValue converts a decoded JSON value to a value of date, time and
+// datetime fields: a string of the layout of their min and max, or of their
+// format when it is a layout, or an integer of epoch formats.
+func (f Field) timeValue(v interface{}) (interface{}, bool) {
+	layout := f.TimeFormat()
+	switch layout {
+	case TimeFormatEpoch, TimeFormatEpochMillis:
+		return int64Value(v)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, false
+	}
+	if layout != "" {
+		_, err := time.Parse(layout, s)
+		return s, err == nil
+	}
+
+	switch f.Type {
+	case FieldTypeDate:
+		layout = dateLayout
+	case FieldTypeTime:
+		layout = timeLayout
+	default:
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return nil, false
+	}
+	return f.formatTime(t), true
+}
+
+func int64Value(v interface{}) (interface{}, bool) {
+	if i, ok := v.(*big.Int); ok && i.IsInt64() {
+		return i.Int64(), true
+	}
+	return nil, false
+}
+
+func float64Value(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, true
+	default:
+		return nil, false
+	}
+}
+
+// IsNullable reports whether the field generates NULL values, at NullableRate
+// or as its default value.
+func (f Field) IsNullable() bool {
+	if f.NullableRate > 0 {
+		return true
+	}
+	if f.UseDefaultRate == 0 {
+		return false
+	}
+	v, err := f.defaultValue()
+	return err == nil && IsNull(v)
+}
+
+// decodeValue decodes a JSON value, integers into *big.Int and other numbers
+// into float64.
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return v, nil
+	}
+	if i, ok := new(big.Int).SetString(n.String(), 10); ok {
+		return i, nil
+	}
+	return n.Float64()
+}
+
+var defaultSnowflakeEpoch = time.Date(2010, 11, 4, 1, 42, 54, 657*int(time.Millisecond), time.UTC)
+
+func (f Field) snowflakeEpoch() (time.Time, error) {
+	if f.Snowflake.Epoch == "" {
+		return defaultSnowflakeEpoch, nil
+	}
+	epoch, err := time.Parse(time.RFC3339, f.Snowflake.Epoch)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse snowflake epoch: %s", err)
+	}
+	if epoch.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("snowflake epoch %s should not be in the future", epoch)
+	}
+	return epoch, nil
+}
+
+// durationRange returns the range of duration fields and the resolution of the
+// generated values.
+func (f Field) durationRange() (min, max, resolution time.Duration, err error) {
+	d := f.Duration
+	if min, err = parseDuration("duration min", d.Min, 0); err != nil {
+		return 0, 0, 0, err
+	}
+	if max, err = parseDuration("duration max", d.Max, defaultDurationMax); err != nil {
+		return 0, 0, 0, err
+	}
+
+	switch d.Unit {
+	case DurationUnitSeconds:
+		resolution = time.Second
+	case DurationUnitMilliseconds:
+		resolution = time.Millisecond
+	case "":
+		resolution = time.Second
+		if min%time.Second != 0 || max%time.Second != 0 {
+			resolution = time.Millisecond
+		}
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid duration unit %q, required (%q / %q)", d.Unit, DurationUnitSeconds, DurationUnitMilliseconds)
+	}
+
+	if min > max {
+		return 0, 0, 0, fmt.Errorf("invalid duration range [%s, %s], required min <= max", min, max)
+	}
+	last := max / resolution * resolution
+	if last > max {
+		last -= resolution
+	}
+	if last < min {
+		return 0, 0, 0, fmt.Errorf("duration range [%s, %s] has no whole %s", min, max, resolution)
+	}
+	return min, max, resolution, nil
+}
+
+func parseDuration(name, value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %s", name, err)
+	}
+	return d, nil
+}
+
+func (f Field) validateMonotonic() error {
+	d := f.DateTime
+	if d.Min == "" {
+		return fmt.Errorf("monotonic datetime requires min")
+	}
+	if d.After != "" || d.BusinessHours || len(d.Weekdays) > 0 {
+		return fmt.Errorf("monotonic datetime should not be after a field or on business hours or weekdays")
+	}
+	interval, jitter, err := f.monotonicStep()
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("datetime interval should be positive, got %s", interval)
+	}
+	if jitter < 0 || jitter >= interval {
+		return fmt.Errorf("invalid datetime jitter %s, required 0 <= jitter < interval %s", jitter, interval)
+	}
+	return nil
+}
+
+// monotonicStep returns the interval and jitter of monotonic datetime fields.
+func (f Field) monotonicStep() (interval, jitter time.Duration, err error) {
+	if interval, err = parseDuration("datetime interval", f.DateTime.Interval, 0); err != nil {
+		return 0, 0, err
+	}
+	if jitter, err = parseDuration("datetime jitter", f.DateTime.Jitter, 0); err != nil {
+		return 0, 0, err
+	}
+	return interval, jitter, nil
+}
+
+// formatTime converts a generated time to the timezone and format of the
+// field.
+func (f Field) formatTime(t time.Time) interface{} {
+	if loc := f.Location(); loc != nil {
+		t = t.In(loc)
+	}
+	switch layout := f.TimeFormat(); layout {
+	case "":
+		return t
+	case TimeFormatEpoch:
+		return t.Unix()
+	case TimeFormatEpochMillis:
+		return t.UnixNano() / int64(time.Millisecond)
+	default:
+		return t.Format(layout)
+	}
+}
+
+func (f Field) padChar() string {
+	if f.String.PadChar == "" {
+		return "0"
+	}
+	return f.String.PadChar
+}
+
+func (f Field) setMax() int {
+	if f.Set.Max == 0 {
+		return len(f.Set.Options)
+	}
+	return f.Set.Max
+}
+
+// AgeMax returns the max of the ages of age fields, which defaults to 100.
+func (f Field) AgeMax() int {
+	if f.Age.Max == 0 {
+		return defaultAgeMax
+	}
+	return f.Age.Max
+}
+
 func (s *Schema) Keys() []string {
 	return s.keys
 }
@@ -161,37 +1584,288 @@ func (s *Schema) IsPrimaryKey(key string) bool {
 	return found
 }
 
-func (f Field) Generate() interface{} {
+// Null is generated for NULL values, so that they are told apart from any other
+// value such as an empty string. It is encoded as JSON null.
+type Null struct{}
 
-	if dataset.Nullable(f.NullableRate) {
-		return nil
+func (Null) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// IsNull reports whether v is NULL, either Null or nil, which rows built
+// otherwise than by generation may hold.
+func IsNull(v interface{}) bool {
+	_, null := v.(Null)
+	return null || v == nil
+}
+
+// Generate generates a value for the field, it fails on a field type it does
+// not support. NULL is generated as Null.
+func (f Field) Generate() (interface{}, error) {
+	return f.GenerateWith(dataset.Default())
+}
+
+// GenerateWith generates a value for the field from the generator g.
+func (f Field) GenerateWith(g *dataset.Generator) (interface{}, error) {
+	return f.generate(g, nil)
+}
+
+// generate generates a value for the field, row holds the values generated so
+// far for the other fields of the row.
+func (f Field) generate(g *dataset.Generator, row map[string]interface{}) (interface{}, error) {
+
+	if g.Nullable(f.NullableRate) {
+		return Null{}, nil
+	}
+	if f.UseDefaultRate > 0 && g.Skip(f.UseDefaultRate) {
+		return f.defaultValue()
 	}
 
 	switch f.Type {
 	case FieldTypeInt:
-		return dataset.IntRange(f.Int.Min, f.Int.Max)
+		i := f.Int
+		min, max := f.IntRange()
+		var v *big.Int
+		switch i.Distribution {
+		case dataset.DistributionNormal, dataset.DistributionExponential:
+			v = g.IntDistribution(min, max, i.Distribution, i.Mean, i.StdDev)
+		default:
+			v = g.IntRange(min, max)
+		}
+		if i.Step > 0 {
+			v = dataset.Multiple(v, min, i.Step)
+		}
+		return v, nil
 	case FieldTypeFloat:
-		return dataset.Float(f.Float.Precision, f.Float.Scale)
+		precision, scale := f.floatPrecision()
+		min, max, bounded := f.floatRange()
+		if !bounded {
+			return g.Float(precision, scale), nil
+		}
+		return g.FloatRange(min, max, scale), nil
+	case FieldTypeULID:
+		min, max, _ := f.dateTimeRange()
+		if min.IsZero() && max.IsZero() {
+			return g.ULID(g.DateTime()), nil
+		}
+		return g.ULID(g.DateTimeRange(min, max)), nil
+	case FieldTypeUsername:
+		return g.Username(f.Username.MaxLength, f.Username.AllowDigits), nil
+	case FieldTypeSlug:
+		n := f.Slug.Words
+		if n == 0 {
+			n = defaultSlugWords
+		}
+		return g.Slug(n), nil
+	case FieldTypeCompany:
+		return g.Company(f.Company.IncludeSuffix), nil
+	case FieldTypePath:
+		depth := f.Path.Depth
+		if depth == 0 {
+			depth = defaultPathDepth
+		}
+		return g.Path(depth, f.Path.Extensions), nil
+	case FieldTypePercent:
+		if f.Percent.AsFraction {
+			return g.FloatRange(0, 1, f.Percent.Scale), nil
+		}
+		return g.FloatRange(0, 100, f.Percent.Scale), nil
 	case FieldTypeDate, FieldTypeDateTime, FieldTypeTime:
-		return dataset.DateTime()
+		var t time.Time
+		min, max, _ := f.dateTimeRange()
+		if after, ok := row[f.DateTime.After].(time.Time); ok && f.Type == FieldTypeDateTime {
+			if min.IsZero() && max.IsZero() {
+				min, max = dataset.DateTimeBounds()
+			}
+			if min.Before(after) {
+				min = after
+			}
+			if max.Before(min) {
+				max = min
+			}
+		}
+		weekdays, _ := f.weekdays()
+		switch {
+		case f.Type == FieldTypeDateTime && (f.DateTime.BusinessHours || len(weekdays) > 0):
+			if min.IsZero() && max.IsZero() {
+				min, max = dataset.DateTimeBounds()
+			}
+			if loc := f.Location(); loc != nil {
+				min, max = min.In(loc), max.In(loc)
+			}
+			t = g.DateTimeBiased(min, max, weekdays, f.DateTime.BusinessHours)
+		case min.IsZero() && max.IsZero():
+			t = g.DateTime()
+		default:
+			t = g.DateTimeRange(min, max)
+		}
+		return f.formatTime(t), nil
 	case FieldTypeJSON:
-		return dataset.JSON()
+		if f.JSON.MaxDepth == 0 && f.JSON.Keys == 0 {
+			return dataset.JSON(), nil
+		}
+		return g.JSONTree(f.JSON.MaxDepth, f.JSON.Keys, f.JSON.ArrayMin, f.JSON.ArrayMax), nil
 	case FieldTypeEnum:
-		return dataset.Enum(f.Enum.Options)
+		if f.Enum.Weights != nil {
+			return g.WeightedEnum(f.Enum.Options, f.Enum.Weights), nil
+		}
+		return g.Enum(f.Enum.Options), nil
 	case FieldTypeSet:
-		return dataset.Set(f.Set.Options)
+		if f.Set.Format == SetFormatJSON {
+			options := g.Subset(f.Set.Options, f.Set.Min, f.setMax())
+			elements := make([]interface{}, len(options))
+			for i, o := range options {
+				elements[i] = o
+			}
+			return elements, nil
+		}
+		return g.Set(f.Set.Options, f.Set.Min, f.setMax()), nil
+	case FieldTypeBool:
+		return g.Bool(f.Bool.TrueRate), nil
+	case FieldTypeUUID:
+		return g.UUID(), nil
+	case FieldTypeCurrencyCode:
+		return g.CurrencyCode(f.CurrencyCode.Only), nil
+	case FieldTypeCountry:
+		return g.Country(f.Country.Format), nil
+	case FieldTypeMoney:
+		return g.Money(f.Money.Min, f.Money.Max, f.Money.Scale), nil
+	case FieldTypeDecimal:
+		return g.Decimal(f.Decimal.Min, f.Decimal.Max, f.Decimal.Scale), nil
+	case FieldTypeEmail:
+		return g.Email(f.Email.Domains), nil
+	case FieldTypePhone:
+		format := f.Phone.Format
+		if format == "" {
+			format, _ = dataset.PhoneFormat(f.Phone.Country)
+		}
+		return g.Phone(format), nil
+	case FieldTypeName:
+		gender, _ := row[f.Name.Gender].(string)
+		return g.GenderedName(f.Name.Part, f.Name.Locale, gender), nil
+	case FieldTypeAddress:
+		return g.Address(f.Address.Part), nil
+	case FieldTypeURL:
+		return g.URL(f.URL.Schemes, f.URL.IncludeQuery), nil
+	case FieldTypeIPv4:
+		var n *net.IPNet
+		if f.IPv4.CIDR != "" {
+			_, n, _ = net.ParseCIDR(f.IPv4.CIDR)
+		}
+		return g.IPv4(n), nil
+	case FieldTypeIPv6:
+		return g.IPv6(), nil
+	case FieldTypeMAC:
+		return g.MAC(f.MAC.Separator), nil
+	case FieldTypeColor:
+		return g.Color(f.Color.IncludeAlpha), nil
+	case FieldTypeGeo:
+		geo := f.Geo
+		precision := geo.Precision
+		if precision == 0 {
+			precision = defaultGeoPrecision
+		}
+		lat, lng := g.Geo(geo.BBox, precision)
+		switch geo.Part {
+		case GeoPartLat:
+			return lat, nil
+		case GeoPartLng:
+			return lng, nil
+		default:
+			return fmt.Sprintf("%.*f,%.*f", precision, lat, precision, lng), nil
+		}
+	case FieldTypeRegex:
+		v, err := g.FromRegex(f.Regex.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("generate from regex %q: %s", f.Regex.Pattern, err)
+		}
+		return v, nil
+	case FieldTypeTemplate:
+		return renderTemplate(f.Template.Expr, row), nil
+	case FieldTypeBinary:
+		b := g.Binary(f.Binary.MinLength, f.Binary.MaxLength)
+		switch f.Binary.Encoding {
+		case BinaryEncodingHex:
+			return hex.EncodeToString(b), nil
+		case BinaryEncodingBase64:
+			return base64.StdEncoding.EncodeToString(b), nil
+		default:
+			return b, nil
+		}
+	case FieldTypeGender:
+		return g.Gender(f.Gender.OtherRate), nil
+	case FieldTypeAge:
+		return int64(g.Age(f.Age.Min, f.AgeMax(), f.Age.Distribution)), nil
+	case FieldTypeDuration:
+		min, max, resolution, err := f.durationRange()
+		if err != nil {
+			return nil, err
+		}
+		d := g.Duration(min, max, resolution)
+		if f.Duration.Unit == "" {
+			return d.String(), nil
+		}
+		return int64(d / resolution), nil
+	case FieldTypeHex:
+		return g.Hex(f.Hex.Length, f.Hex.Upper), nil
+	case FieldTypeBase64:
+		b := g.Binary(f.Base64.MinBytes, f.Base64.MaxBytes)
+		if f.Base64.URLSafe {
+			return base64.URLEncoding.EncodeToString(b), nil
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case FieldTypeArray:
+		a := f.Array
+		elements := make([]interface{}, g.Length(a.MinLength, a.MaxLength))
+		for i := range elements {
+			v, err := a.Element.generate(g, nil)
+			if err != nil {
+				return nil, fmt.Errorf("generate array element: %s", err)
+			}
+			elements[i] = v
+		}
+		return elements, nil
+	case FieldTypeObject:
+		order, _ := generationOrder(f.Object.Fields)
+		object := make(map[string]interface{}, len(order))
+		for _, k := range order {
+			v, err := f.Object.Fields[k].generate(g, object)
+			if err != nil {
+				return nil, fmt.Errorf("generate object field %q: %s", k, err)
+			}
+			object[k] = v
+		}
+		return object, nil
+	case FieldTypeForeignKey:
+		return nil, fmt.Errorf("foreign key field refers to %s, it should be generated by GenerateDataset", f.reference())
+	case FieldTypeSequence, FieldTypeSnowflake:
+		return nil, fmt.Errorf("%s field should be generated by a schema", f.Type)
+	case FieldTypeConst:
+		return f.constValue()
 	case FieldTypeString:
 		s := f.String
+		var v string
 		switch s.Type {
 		case StringTypeAscii:
-			return dataset.Ascii(s.Ascii.MinLength, s.Ascii.MaxLength)
+			v = g.Ascii(s.Ascii.MinLength, s.Ascii.MaxLength, s.Ascii.Charset)
 		case StringTypeWord:
-			return dataset.WordN(s.Word.Num)
+			v = g.WordN(s.Word.Num)
 		case StringTypeSentence:
-			return dataset.SentenceN(s.Sentence.Num)
+			v = g.SentenceN(s.Sentence.Num)
 		case StringTypeParagraph:
-			return dataset.ParagraphN(s.Paragraph.Num)
+			v = g.ParagraphN(s.Paragraph.Num)
+		case StringTypeUnicode:
+			v = g.Unicode(s.Unicode.MinLength, s.Unicode.MaxLength, s.Unicode.Blocks)
+		default:
+			return nil, fmt.Errorf("unsupported string type %q", s.Type)
+		}
+		if n := s.PadTo - utf8.RuneCountInString(v); n > 0 {
+			v = strings.Repeat(f.padChar(), n) + v
 		}
+		return s.Prefix + v + s.Suffix, nil
+	}
+	if gen, found := registeredType(f.Type); found {
+		return gen(g, f)
 	}
-	panic(fmt.Sprintf("invalid field type: %s", f.Type))
+	return nil, fmt.Errorf("unsupported field type %q", f.Type)
 }
@@ -0,0 +1,312 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/luncj/mess/dataset"
+)
+
+func TestFloatUnboundedScale(t *testing.T) {
+	f := Field{Type: FieldTypeFloat}
+	f.Float.Precision, f.Float.Scale = 12, 3
+	for i := 0; i < 100; i++ {
+		v, err := f.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scaled := v.(float64) * 1000; math.Abs(scaled-math.Round(scaled)) > 1e-3 {
+			t.Fatalf("got %v, want 3 decimals", v)
+		}
+	}
+}
+
+func TestGenerateDateTimeUTC(t *testing.T) {
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+
+	generate := func(local *time.Location) []interface{} {
+		time.Local = local
+		dataset.Seed(7)
+		values := make([]interface{}, 100)
+		for i := range values {
+			v, err := Field{Type: FieldTypeDateTime}.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values[i] = v
+		}
+		return values
+	}
+
+	utc := generate(time.UTC)
+	for _, v := range utc {
+		if loc := v.(time.Time).Location(); loc != time.UTC {
+			t.Fatalf("got datetime in %s, want UTC", loc)
+		}
+	}
+	if !reflect.DeepEqual(utc, generate(time.FixedZone("UTC+13", 13*60*60))) {
+		t.Fatal("datetimes generated in another local timezone differ")
+	}
+}
+
+func TestFloatBoundsPrecision(t *testing.T) {
+	tests := []struct {
+		min, max float64
+		valid    bool
+	}{
+		{0, 1e12, false},
+		{-1e8, 0, false},
+		{-99999999.99, 99999999.99, true},
+	}
+	for _, test := range tests {
+		f := Field{Type: FieldTypeFloat}
+		f.Float.Min, f.Float.Max = &test.min, &test.max
+		f.Float.Precision, f.Float.Scale = 10, 2
+		err := f.validate()
+		if test.valid && err != nil {
+			t.Errorf("[%v, %v]: %s", test.min, test.max, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("[%v, %v]: got no error of bounds out of precision 10 and scale 2", test.min, test.max)
+		}
+	}
+}
+
+func TestGenerateNullAndEmptyString(t *testing.T) {
+	f := Field{Type: FieldTypeString}
+	f.String.Type = StringTypeAscii
+	f.String.Ascii.MaxLength = 1
+
+	v, err := f.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "" {
+		t.Errorf("got %#v, want an empty string", v)
+	}
+
+	f.NullableRate = 100
+	if v, err = f.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	if v != (Null{}) {
+		t.Errorf("got %#v, want Null", v)
+	}
+	if IsNull("") {
+		t.Error("an empty string is NULL")
+	}
+}
+
+func intField(min, max int64, exclusiveMin, exclusiveMax bool) Field {
+	f := Field{Type: FieldTypeInt}
+	f.Int.Min, f.Int.Max = big.NewInt(min), big.NewInt(max)
+	f.Int.ExclusiveMin, f.Int.ExclusiveMax = exclusiveMin, exclusiveMax
+	return f
+}
+
+func floatField(min, max float64, exclusiveMin, exclusiveMax bool) Field {
+	f := Field{Type: FieldTypeFloat}
+	f.Float.Min, f.Float.Max = &min, &max
+	f.Float.Precision, f.Float.Scale = 10, 2
+	f.Float.ExclusiveMin, f.Float.ExclusiveMax = exclusiveMin, exclusiveMax
+	return f
+}
+
+func TestExclusiveBoundsEmpty(t *testing.T) {
+	tests := map[string]Field{
+		"int [5, 5)":         intField(5, 5, false, true),
+		"int (5, 5]":         intField(5, 5, true, false),
+		"int (5, 6)":         intField(5, 6, true, true),
+		"float [1.5, 1.5)":   floatField(1.5, 1.5, false, true),
+		"float (1.5, 1.5]":   floatField(1.5, 1.5, true, false),
+		"float (1.50, 1.51)": floatField(1.5, 1.51, true, true),
+	}
+	for name, f := range tests {
+		if err := f.validate(); err == nil {
+			t.Errorf("%s: got no error of the empty range", name)
+		}
+	}
+}
+
+func TestExclusiveBoundsEndpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Field
+		want interface{}
+	}{
+		{"int (5, 6]", intField(5, 6, true, false), big.NewInt(6)},
+		{"int [5, 6)", intField(5, 6, false, true), big.NewInt(5)},
+		{"float (1.50, 1.51]", floatField(1.5, 1.51, true, false), 1.51},
+		{"float [1.50, 1.51)", floatField(1.5, 1.51, false, true), 1.5},
+	}
+	for _, test := range tests {
+		if err := test.f.validate(); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		for i := 0; i < 100; i++ {
+			v, err := test.f.Generate()
+			if err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+			if !reflect.DeepEqual(v, test.want) {
+				t.Fatalf("%s: got %v, want %v", test.name, v, test.want)
+			}
+		}
+	}
+}
+
+func TestExclusiveBoundsAdjacent(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Field
+		want []interface{}
+	}{
+		{"int (4, 7)", intField(4, 7, true, true), []interface{}{big.NewInt(5), big.NewInt(6)}},
+		{"float (1.50, 1.53)", floatField(1.5, 1.53, true, true), []interface{}{1.51, 1.52}},
+	}
+	for _, test := range tests {
+		if err := test.f.validate(); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		seen := make([]bool, len(test.want))
+		for i := 0; i < 200; i++ {
+			v, err := test.f.Generate()
+			if err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+			found := false
+			for j, want := range test.want {
+				if reflect.DeepEqual(v, want) {
+					seen[j], found = true, true
+				}
+			}
+			if !found {
+				t.Fatalf("%s: got %v, want one of %v", test.name, v, test.want)
+			}
+		}
+		for j, want := range test.want {
+			if !seen[j] {
+				t.Errorf("%s: never got %v", test.name, want)
+			}
+		}
+	}
+}
+
+func TestHexLength(t *testing.T) {
+	f := Field{Type: FieldTypeHex}
+	if err := f.validate(); err == nil {
+		t.Error("got no error for a hex field without length")
+	}
+	f.Hex.Length = 8
+	if err := f.validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDefaultValueTypes(t *testing.T) {
+	field := func(typ FieldType, def string) Field {
+		f := Field{Type: typ, Default: json.RawMessage(def), UseDefaultRate: 100}
+		f.Float.Min, f.Float.Max = new(float64), new(float64)
+		f.String.Ascii.MaxLength = 10
+		return f
+	}
+	datetime := field(FieldTypeDateTime, `"2020-01-02T03:04:05Z"`)
+	epoch := field(FieldTypeDate, `1577836800`)
+	epoch.Date.Format = TimeFormatEpoch
+
+	tests := []struct {
+		name string
+		f    Field
+		want interface{}
+	}{
+		{"int", field(FieldTypeInt, `0`), big.NewInt(0)},
+		{"float", field(FieldTypeFloat, `0`), 0.0},
+		{"string", field(FieldTypeString, `"none"`), "none"},
+		{"datetime", datetime, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"date", field(FieldTypeDate, `"2020-01-02"`), time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"epoch date", epoch, int64(1577836800)},
+		{"null", field(FieldTypeFloat, `null`), Null{}},
+	}
+	for _, test := range tests {
+		if err := test.f.validate(); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		v, err := test.f.Generate()
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !reflect.DeepEqual(v, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.name, v, test.want)
+		}
+	}
+
+	for _, f := range []Field{
+		field(FieldTypeInt, `"0"`),
+		field(FieldTypeFloat, `"zero"`),
+		field(FieldTypeDate, `"02/01/2020"`),
+		field(FieldTypeBool, `1`),
+		field(FieldTypeString, `0`),
+	} {
+		if err := f.validate(); err == nil {
+			t.Errorf("got no error for default %s of %s field", f.Default, f.Type)
+		}
+	}
+}
+
+func TestDefaultPrecedence(t *testing.T) {
+	f := Field{Type: FieldTypeInt, Default: json.RawMessage(`-1`)}
+	f.Int.Min, f.Int.Max = big.NewInt(0), big.NewInt(10)
+
+	tests := []struct {
+		nullableRate, useDefaultRate int
+		want                         func(v interface{}) bool
+	}{
+		// NULL comes before the default value.
+		{100, 100, IsNull},
+		// The default value comes before a generated one.
+		{0, 100, func(v interface{}) bool { return reflect.DeepEqual(v, big.NewInt(-1)) }},
+		{0, 0, func(v interface{}) bool { return v.(*big.Int).Sign() >= 0 }},
+	}
+	for _, test := range tests {
+		f.NullableRate, f.UseDefaultRate = test.nullableRate, test.useDefaultRate
+		for i := 0; i < 100; i++ {
+			v, err := f.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.want(v) {
+				t.Fatalf("nullable rate %d, use default rate %d: got %v", test.nullableRate, test.useDefaultRate, v)
+			}
+		}
+	}
+}
+
+func TestAgeMinOnly(t *testing.T) {
+	path, remove := schemaFile(t, `{
+		"table": "users",
+		"primary_keys": ["id"],
+		"fields": {
+			"id": {"type": "sequence"},
+			"age": {"type": "age", "age": {"min": 18}}
+		}
+	}`)
+	defer remove()
+
+	s, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := s.GenerateRows(500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if age := row["age"].(int64); age < 18 || age > defaultAgeMax {
+			t.Fatalf("got age %d, want within [18, %d]", age, defaultAgeMax)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/luncj/mess/dataset"
+)
+
+const defaultMaxRetries = 100
+
+// ErrKeySpaceExhausted is returned when no row with unique values of a
+// primary key or unique key is generated in Attempts attempts.
+type ErrKeySpaceExhausted struct {
+	// Field lists the fields of the key separated by commas.
+	Field    string
+	Attempts int
+}
+
+func (e ErrKeySpaceExhausted) Error() string {
+	return fmt.Sprintf("no unique values of key (%s) generated after %d attempts", e.Field, e.Attempts)
+}
+
+func (s *Schema) maxRetries() int {
+	if s.MaxRetries > 0 {
+		return s.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+// uniqueKeys returns the primary key and the unique keys, whose values have
+// to be unique among the generated rows.
+func (s *Schema) uniqueKeys() [][]string {
+	if len(s.PrimaryKeys) == 0 {
+		return s.UniqueKeys
+	}
+	return append([][]string{s.PrimaryKeys}, s.UniqueKeys...)
+}
+
+// conflict returns the primary key or unique key of which row has the values
+// of a row generated before, otherwise it marks the values of row as generated
+// and returns nil. Unique keys with any NULL value never conflict.
+func (s *Schema) conflict(row map[string]interface{}) []string {
+	groups := s.uniqueKeys()
+	values := make([]string, len(groups))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, keys := range groups {
+		if hasNull(keys, row) {
+			continue
+		}
+		values[i] = uniqueKey(keys, row)
+		if s.generated[i][values[i]] {
+			return keys
+		}
+	}
+
+	for i, v := range values {
+		if v != "" {
+			s.generated[i][v] = true
+		}
+	}
+	return nil
+}
+
+func hasNull(keys []string, row map[string]interface{}) bool {
+	for _, k := range keys {
+		if IsNull(row[k]) {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueKey(keys []string, row map[string]interface{}) string {
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = fmt.Sprintf("%s=%v", k, row[k])
+	}
+	return strings.Join(values, "&")
+}
+
+// isUnique reports whether the field k is part of the primary key or a unique
+// key.
+func (s *Schema) isUnique(k string) bool {
+	for _, keys := range s.uniqueKeys() {
+		for _, key := range keys {
+			if key == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// uniqueSuffix suffixes the value v of the field k with sep and the number of
+// times it was generated before, if any, cutting v so that the suffixed value
+// has at most maxLength characters when maxLength is positive.
+func (s *Schema) uniqueSuffix(k, v, sep string, maxLength int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := s.suffixes[k]
+	if counts == nil {
+		counts = make(map[string]int)
+		s.suffixes[k] = counts
+	}
+	n := counts[v]
+	counts[v]++
+	if n == 0 {
+		return v
+	}
+	suffix := fmt.Sprintf("%s%d", sep, n+1)
+	if maxLength > 0 {
+		if n := maxLength - len(suffix); n > 0 {
+			v = dataset.Truncate(v, n)
+		} else {
+			v = ""
+		}
+	}
+	return v + suffix
+}
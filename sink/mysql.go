@@ -0,0 +1,140 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+// MySQLSink inserts generated rows into MySQL, every batch of rows is inserted
+// by one INSERT statement within its own transaction.
+type MySQLSink struct {
+	// BatchSize defaults to 1,000 rows.
+	BatchSize int
+	// Retries is the number of times a batch is retried after a deadlock,
+	// waiting RetryBackoff, 100ms by default, doubled after every retry.
+	Retries      int
+	RetryBackoff time.Duration
+	// Truncate empties the table before the first batch. TRUNCATE TABLE
+	// commits implicitly in MySQL, so it runs outside the batch transactions.
+	Truncate bool
+
+	db *sql.DB
+	s  *schema.Schema
+}
+
+func NewMySQLSink(db *sql.DB, s *schema.Schema) *MySQLSink {
+	return &MySQLSink{
+		db: db,
+		s:  s,
+	}
+}
+
+// Load generates and inserts count rows, it returns the number of rows
+// committed before any error.
+func (m *MySQLSink) Load(ctx context.Context, count int) (int, error) {
+	size := m.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	if m.Truncate {
+		if _, err := m.db.ExecContext(ctx, fmt.Sprintf("TRUNCATE TABLE %s", quoteMySQL(m.s.Table))); err != nil {
+			return 0, fmt.Errorf("truncate table: %s", err)
+		}
+	}
+
+	loaded := 0
+	for loaded < count {
+		if err := ctx.Err(); err != nil {
+			return loaded, err
+		}
+
+		n := size
+		if rest := count - loaded; rest < n {
+			n = rest
+		}
+		if err := m.insertBatch(ctx, n); err != nil {
+			return loaded, err
+		}
+		loaded += n
+	}
+
+	return loaded, nil
+}
+
+func (m *MySQLSink) insertBatch(ctx context.Context, n int) error {
+	keys := m.s.Keys()
+
+	args := make([]interface{}, 0, n*len(keys))
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := m.s.GenerateRow()
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			v, err := driverValue(m.s.Fields[k], row[k])
+			if err != nil {
+				return err
+			}
+			args = append(args, v)
+		}
+	}
+
+	return withRetry(ctx, m.Retries, m.RetryBackoff, func() error {
+		return m.insertRows(ctx, n, args)
+	})
+}
+
+func (m *MySQLSink) insertRows(ctx context.Context, n int, args []interface{}) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, m.insertSQL(n), args...); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("insert rows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (m *MySQLSink) insertSQL(n int) string {
+	keys := m.s.Keys()
+
+	columns := make([]string, len(keys))
+	for i, k := range keys {
+		columns[i] = quoteMySQL(k)
+	}
+
+	placeholders := fmt.Sprintf("(%s)", strings.TrimSuffix(strings.Repeat("?,", len(keys)), ","))
+	values := make([]string, n)
+	for i := range values {
+		values[i] = placeholders
+	}
+
+	sql := strings.Builder{}
+	{
+		sql.WriteString(fmt.Sprintf("INSERT INTO %s (", quoteMySQL(m.s.Table)))
+		sql.WriteString(strings.Join(columns, ","))
+		sql.WriteString(") VALUES ")
+		sql.WriteString(strings.Join(values, ","))
+	}
+	return sql.String()
+}
+
+func quoteMySQL(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", "``"))
+}
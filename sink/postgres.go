@@ -0,0 +1,202 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luncj/mess/output"
+	"github.com/luncj/mess/schema"
+)
+
+// PostgresSink streams generated rows into PostgreSQL with COPY FROM STDIN,
+// every batch of rows is copied within its own transaction.
+//
+// The driver of db should support COPY through prepared statements as
+// github.com/lib/pq does: the statement is prepared once per batch, executed
+// once per row and finally executed without arguments to flush the rows.
+type PostgresSink struct {
+	// BatchSize defaults to 1,000 rows.
+	BatchSize int
+	// Retries is the number of times a batch is retried after a deadlock or
+	// serialization failure, waiting RetryBackoff, 100ms by default, doubled
+	// after every retry.
+	Retries      int
+	RetryBackoff time.Duration
+	// Truncate empties the table within the transaction of the first batch,
+	// TruncateCascade also restarts its identity sequences and truncates the
+	// tables referring to it.
+	Truncate        bool
+	TruncateCascade bool
+
+	db *sql.DB
+	s  *schema.Schema
+}
+
+func NewPostgresSink(db *sql.DB, s *schema.Schema) *PostgresSink {
+	return &PostgresSink{
+		db: db,
+		s:  s,
+	}
+}
+
+// Load generates and copies count rows, it returns the number of rows
+// committed before any error.
+func (p *PostgresSink) Load(ctx context.Context, count int) (int, error) {
+	size := p.BatchSize
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+
+	truncate := p.Truncate
+	loaded := 0
+	for loaded < count || truncate {
+		if err := ctx.Err(); err != nil {
+			return loaded, err
+		}
+
+		n := size
+		if rest := count - loaded; rest < n {
+			n = rest
+		}
+		if err := p.copyBatch(ctx, n, truncate); err != nil {
+			return loaded, err
+		}
+		truncate = false
+		loaded += n
+	}
+
+	return loaded, nil
+}
+
+func (p *PostgresSink) copyBatch(ctx context.Context, n int, truncate bool) error {
+	keys := p.s.Keys()
+
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := p.s.GenerateRow()
+		if err != nil {
+			return err
+		}
+		rows[i] = make([]interface{}, len(keys))
+		for j, k := range keys {
+			if rows[i][j], err = copyText(p.s.Fields[k], row[k]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return withRetry(ctx, p.Retries, p.RetryBackoff, func() error {
+		return p.copyRows(ctx, rows, truncate)
+	})
+}
+
+func (p *PostgresSink) copyRows(ctx context.Context, rows [][]interface{}, truncate bool) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if truncate {
+		if _, err := tx.ExecContext(ctx, p.truncateSQL()); err != nil {
+			return fmt.Errorf("truncate table: %w", err)
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, p.copySQL())
+	if err != nil {
+		return fmt.Errorf("prepare copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, values := range rows {
+		if _, err := stmt.ExecContext(ctx, values...); err != nil {
+			return fmt.Errorf("copy row: %w", err)
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("flush copy: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresSink) copySQL() string {
+	keys := p.s.Keys()
+
+	columns := make([]string, len(keys))
+	for i, k := range keys {
+		columns[i] = quotePostgres(k)
+	}
+
+	return fmt.Sprintf("COPY %s (%s) FROM STDIN", quotePostgres(p.s.Table), strings.Join(columns, ","))
+}
+
+func (p *PostgresSink) truncateSQL() string {
+	sql := fmt.Sprintf("TRUNCATE TABLE %s", quotePostgres(p.s.Table))
+	if p.TruncateCascade {
+		sql += " RESTART IDENTITY CASCADE"
+	}
+	return sql
+}
+
+func quotePostgres(name string) string {
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(name, `"`, `""`))
+}
+
+// copyText converts a generated value to the text representation of COPY, the
+// driver escapes it and writes nil as \N.
+func copyText(f schema.Field, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, schema.Null:
+		return nil, nil
+	case bool:
+		if v {
+			return "t", nil
+		}
+		return "f", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', f.Scale(), 64), nil
+	case []byte:
+		return fmt.Sprintf(`\x%x`, v), nil
+	case []interface{}:
+		if f.Type == schema.FieldTypeArray {
+			return output.PostgresArray(f, v)
+		}
+		return driverValue(f, v)
+	case time.Time:
+		switch f.Type {
+		case schema.FieldTypeDate:
+			return v.Format("2006-01-02"), nil
+		case schema.FieldTypeTime:
+			return v.Format("15:04:05"), nil
+		default:
+			if f.Location() != nil {
+				return v.Format("2006-01-02 15:04:05-07:00"), nil
+			}
+			return v.Format("2006-01-02 15:04:05"), nil
+		}
+	default:
+		d, err := driverValue(f, v)
+		if err != nil {
+			return nil, err
+		}
+		return fmt.Sprint(d), nil
+	}
+}
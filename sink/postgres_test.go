@@ -0,0 +1,30 @@
+package sink
+
+import (
+	"testing"
+
+	"github.com/luncj/mess/schema"
+)
+
+func TestCopyTextArray(t *testing.T) {
+	f := schema.Field{Type: schema.FieldTypeArray}
+	f.Array.Element = &schema.Field{Type: schema.FieldTypeInt}
+	v, err := copyText(f, []interface{}{int64(1), nil, int64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"1",NULL,"3"}`; v != want {
+		t.Errorf("got %#v, want %q", v, want)
+	}
+
+	// Sets of the json format stay JSON.
+	set := schema.Field{Type: schema.FieldTypeSet}
+	set.Set.Format = schema.SetFormatJSON
+	v, err = copyText(set, []interface{}{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `["a","b"]`; v != want {
+		t.Errorf("got %#v, want %q", v, want)
+	}
+}
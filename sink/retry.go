@@ -0,0 +1,60 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// withRetry runs the transaction txn, and reruns it up to retries times after a
+// deadlock or serialization failure, waiting backoff before the first rerun and
+// twice as long before every next one.
+func withRetry(ctx context.Context, retries int, backoff time.Duration, txn func() error) error {
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for i := 0; ; i++ {
+		err := txn()
+		if err == nil || i >= retries || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether err is a deadlock or serialization failure: MySQL
+// error 1213 and PostgreSQL SQLSTATE 40P01 or 40001. Drivers are recognized by
+// the shape of their errors, github.com/go-sql-driver/mysql by the Number field
+// and github.com/lib/pq and pgx by the SQLState method.
+func retryable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(interface{ SQLState() string }); ok {
+			switch e.SQLState() {
+			case "40P01", "40001":
+				return true
+			}
+		}
+
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		if n := v.FieldByName("Number"); n.IsValid() && n.Kind() == reflect.Uint16 && n.Uint() == 1213 {
+			return true
+		}
+	}
+	return false
+}
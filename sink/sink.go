@@ -0,0 +1,36 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/luncj/mess/schema"
+)
+
+const defaultBatchSize = 1_000
+
+// driverValue converts a generated value to a value database/sql drivers
+// accept as argument.
+func driverValue(f schema.Field, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, bool, int64, float64, string, []byte, time.Time:
+		return v, nil
+	case schema.Null:
+		return nil, nil
+	case *big.Int:
+		if v.IsInt64() {
+			return v.Int64(), nil
+		}
+		return v.String(), nil
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s: %s", f.Type, err)
+		}
+		return string(b), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}